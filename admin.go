@@ -0,0 +1,218 @@
+package booga
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// Status is cluster status reported by admin endpoint.
+type Status struct {
+	Ready    bool          `json:"ready"`
+	Services []ServiceInfo `json:"services"`
+}
+
+func (c *Cluster) isReady() bool {
+	select {
+	case <-c.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+//go:embed dashboard.html
+var dashboard []byte
+
+// memberUnreachable is reported by Roles for members of replica set that
+// can't be queried.
+const memberUnreachable = "UNREACHABLE"
+
+// Roles returns replica set member state (e.g. PRIMARY or SECONDARY) by
+// member address for config server and every shard.
+func (c *Cluster) Roles(ctx context.Context) map[string]string {
+	t := c.Topology()
+	roles := map[string]string{}
+	for _, rs := range append([]ReplicaSet{t.ConfigServer}, t.Shards...) {
+		if err := withClient(ctx, replicaSetURI(c.userinfo(), rs.Name, rs.Members), func(client *mongo.Client) error {
+			status, err := replSetGetStatus(ctx, client)
+			if err != nil {
+				return err
+			}
+			for _, m := range status.Members {
+				roles[m.Name] = m.StateStr
+			}
+			return nil
+		}); err != nil {
+			for _, addr := range rs.Members {
+				roles[addr] = memberUnreachable
+			}
+		}
+	}
+
+	return roles
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (c *Cluster) handleLogs(w http.ResponseWriter, r *http.Request, name string) {
+	if !c.hasService(name) {
+		http.Error(w, "no service "+name, http.StatusNotFound)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	entries, unsubscribe := c.logs.subscribe(name)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-entries:
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// handleService handles /services/{name}/{action} requests.
+func (c *Cluster) handleService(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/services/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	name, action := parts[0], parts[1]
+
+	if action == "logs" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		c.handleLogs(w, r, name)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var err error
+	switch action {
+	case "kill":
+		err = c.Kill(name)
+	case "restart":
+		err = c.Restart(name)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Handler returns HTTP handler of admin endpoint:
+//
+//	GET  /                        web dashboard
+//	GET  /status                  cluster readiness and services
+//	GET  /topology                cluster members
+//	GET  /roles                   replica set member states, see Roles
+//	GET  /stats                   chunk distribution, see Stats
+//	POST /services/{name}/kill    kill service
+//	POST /services/{name}/restart restart service
+//	GET  /services/{name}/logs    stream service log entries as NDJSON
+func (c *Cluster) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, Status{
+			Ready:    c.isReady(),
+			Services: c.Services(),
+		})
+	})
+	mux.HandleFunc("/topology", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, c.Topology())
+	})
+	mux.HandleFunc("/roles", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*2)
+		defer cancel()
+
+		writeJSON(w, c.Roles(ctx))
+	})
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		stats, err := c.Stats(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, stats)
+	})
+	mux.HandleFunc("/services/", c.handleService)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(dashboard)
+	})
+
+	return mux
+}
+
+// serveAdmin serves admin endpoint on c.adminAddr until context
+// cancellation.
+func (c *Cluster) serveAdmin(ctx context.Context) error {
+	return c.serveHTTP(ctx, "admin", c.adminAddr, c.Handler())
+}
+
+// serveHTTP serves handler on addr until context cancellation.
+func (c *Cluster) serveHTTP(ctx context.Context, name, addr string, h http.Handler) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return xerrors.Errorf("listen: %w", err)
+	}
+	srv := &http.Server{Handler: h}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	c.log.Info("Serving "+name+" endpoint", zap.String("addr", ln.Addr().String()))
+	if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+		return xerrors.Errorf("serve: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package booga
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+)
+
+// oplogArchivePath returns path of oplog archive of replica set in dir.
+//
+// Archive is sequence of raw BSON oplog entries, as produced by mongodump.
+func oplogArchivePath(dir, rsName string) string {
+	return filepath.Join(dir, rsName+".oplog.bson")
+}
+
+// ArchiveOplog appends oplog entries of every shard to files in dir until
+// context cancellation, starting from entries written after call.
+//
+// If tailing stops, e.g. on primary change, it is resumed after last
+// archived entry. Returns error if tailing can't be resumed without gap.
+func (c *Cluster) ArchiveOplog(ctx context.Context, dir string) error {
+	if err := ensureDir(dir); err != nil {
+		return xerrors.Errorf("ensure dir: %w", err)
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	for shardID := 0; shardID < c.shards; shardID++ {
+		shardID := shardID
+		g.Go(func() error {
+			rsName := c.shardReplicaSet(shardID)
+			f, err := os.OpenFile(oplogArchivePath(dir, rsName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+			if err != nil {
+				return xerrors.Errorf("open: %w", err)
+			}
+			defer func() { _ = f.Close() }()
+
+			var after *primitive.Timestamp
+			for {
+				entries, from, err := c.tailOplog(gCtx, shardID, after)
+				if err != nil {
+					return xerrors.Errorf("tail %s: %w", rsName, err)
+				}
+				after = &from
+				for e := range entries {
+					if _, err := f.Write(e); err != nil {
+						return xerrors.Errorf("write %s: %w", rsName, err)
+					}
+					t, i, ok := e.Lookup("ts").TimestampOK()
+					if !ok {
+						return xerrors.Errorf("entry of %s has no timestamp", rsName)
+					}
+					after = &primitive.Timestamp{T: t, I: i}
+				}
+
+				// Tailing stopped before cancellation.
+				if !sleep(gCtx, time.Second) {
+					break
+				}
+				c.log.Info("Resuming oplog archiving",
+					zap.String("rs", rsName),
+					zap.Uint32("ts", after.T),
+				)
+			}
+
+			return f.Close()
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// archiveOplog runs ArchiveOplog to Config.OplogArchiveDir after cluster
+// is ready.
+func (c *Cluster) archiveOplog(ctx context.Context) {
+	select {
+	case <-c.ready:
+	case <-ctx.Done():
+		return
+	}
+
+	c.log.Info("Archiving oplog", zap.String("dir", c.oplogArchiveDir))
+	if err := c.ArchiveOplog(ctx, c.oplogArchiveDir); err != nil && ctx.Err() == nil {
+		c.log.Warn("Oplog archiving failed", zap.Error(err))
+	}
+}
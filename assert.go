@@ -0,0 +1,71 @@
+package booga
+
+import (
+	"context"
+	"time"
+)
+
+// TestingT is subset of testing.TB used by assertion helpers.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// assertTimeout is timeout of single assertion.
+const assertTimeout = time.Second * 30
+
+func (c *Cluster) namespaceStats(t TestingT, ns string) NamespaceStats {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), assertTimeout)
+	defer cancel()
+
+	stats, err := c.Stats(ctx)
+	if err != nil {
+		t.Fatalf("booga: stats: %v", err)
+	}
+	s, ok := stats.Namespace(ns)
+	if !ok {
+		t.Fatalf("booga: namespace %s is not sharded", ns)
+	}
+
+	return s
+}
+
+// AssertShardedAcross asserts that documents of ns are stored on at
+// least minShards shards.
+func (c *Cluster) AssertShardedAcross(t TestingT, ns string, minShards int) {
+	t.Helper()
+	checkShardedAcross(t, c.namespaceStats(t, ns), minShards)
+}
+
+func checkShardedAcross(t TestingT, s NamespaceStats, minShards int) {
+	t.Helper()
+
+	var shards int
+	for _, shard := range s.Shards {
+		if shard.Documents > 0 {
+			shards++
+		}
+	}
+	if shards < minShards {
+		t.Errorf("booga: %s has documents on %d shards (%+v), expected at least %d",
+			s.Namespace, shards, s.Shards, minShards,
+		)
+	}
+}
+
+// AssertChunkCount asserts that ns has exactly want chunks.
+func (c *Cluster) AssertChunkCount(t TestingT, ns string, want int) {
+	t.Helper()
+	checkChunkCount(t, c.namespaceStats(t, ns), want)
+}
+
+func checkChunkCount(t TestingT, s NamespaceStats, want int) {
+	t.Helper()
+
+	if got := s.Chunks(); got != want {
+		t.Errorf("booga: %s has %d chunks (%+v), expected %d", s.Namespace, got, s.Shards, want)
+	}
+}
@@ -0,0 +1,53 @@
+package booga
+
+import (
+	"fmt"
+	"testing"
+)
+
+// recorder is TestingT that records failures.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestCheckDistribution(t *testing.T) {
+	stats := NamespaceStats{
+		Namespace: "cloud.users",
+		Shards: []ShardStats{
+			{Shard: "rsData0", Documents: 10, Chunks: 2},
+			{Shard: "rsData1", Documents: 0, Chunks: 1},
+			{Shard: "rsData2", Documents: 5, Chunks: 1},
+		},
+	}
+	for _, tt := range []struct {
+		Name   string
+		Check  func(t TestingT)
+		Failed bool
+	}{
+		{Name: "AcrossAll", Check: func(t TestingT) { checkShardedAcross(t, stats, 3) }, Failed: true},
+		{Name: "AcrossPopulated", Check: func(t TestingT) { checkShardedAcross(t, stats, 2) }},
+		{Name: "AcrossOne", Check: func(t TestingT) { checkShardedAcross(t, stats, 1) }},
+		{Name: "AcrossEmpty", Check: func(t TestingT) { checkShardedAcross(t, NamespaceStats{}, 1) }, Failed: true},
+		{Name: "ChunksEqual", Check: func(t TestingT) { checkChunkCount(t, stats, 4) }},
+		{Name: "ChunksLess", Check: func(t TestingT) { checkChunkCount(t, stats, 3) }, Failed: true},
+		{Name: "ChunksMore", Check: func(t TestingT) { checkChunkCount(t, stats, 5) }, Failed: true},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			r := &recorder{}
+			tt.Check(r)
+			if failed := len(r.errors) > 0; failed != tt.Failed {
+				t.Errorf("failed: %v, expected %v, errors %v", failed, tt.Failed, r.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package booga
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// Credentials of cluster user, see Config.Auth.
+type Credentials struct {
+	Username string `json:"username"` // "booga" by default
+	Password string `json:"password"` // "booga" by default
+}
+
+func (cr Credentials) withDefaults() Credentials {
+	if cr.Username == "" {
+		cr.Username = "booga"
+	}
+	if cr.Password == "" {
+		cr.Password = "booga"
+	}
+	return cr
+}
+
+// keyFileName is name of key file that members use for internal
+// authentication, in base directory.
+const keyFileName = "keyfile"
+
+// writeKeyFile writes random key file shared by every member of cluster
+// and returns its absolute path.
+func (c *Cluster) writeKeyFile() (string, error) {
+	if err := ensureDir(c.dir); err != nil {
+		return "", err
+	}
+	name, err := filepath.Abs(filepath.Join(c.dir, keyFileName))
+	if err != nil {
+		return "", xerrors.Errorf("path: %w", err)
+	}
+
+	key := make([]byte, 96)
+	if _, err := rand.Read(key); err != nil {
+		return "", xerrors.Errorf("rand: %w", err)
+	}
+	// Key file of previous run is read-only.
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return "", xerrors.Errorf("remove: %w", err)
+	}
+	// Members reject key file that is accessible by group or others.
+	if err := ioutil.WriteFile(name, []byte(base64.StdEncoding.EncodeToString(key)), 0400); err != nil {
+		return "", xerrors.Errorf("write: %w", err)
+	}
+	if c.runAs != nil {
+		if err := chown(name, c.runAs); err != nil {
+			return "", xerrors.Errorf("chown: %w", err)
+		}
+	}
+
+	return name, nil
+}
+
+// userinfo returns credentials of cluster user for connection strings,
+// nil if access control is disabled.
+func (c *Cluster) userinfo() *url.Userinfo {
+	if c.auth == nil {
+		return nil
+	}
+	return url.UserPassword(c.auth.Username, c.auth.Password)
+}
+
+// codeUnauthorized is server error code of command that requires
+// authentication.
+const codeUnauthorized = 13
+
+// isUnauthorized reports whether err is authorization failure, e.g. when
+// localhost exception no longer applies because users exist.
+func isUnauthorized(err error) bool {
+	var cmdErr mongo.CommandError
+	return xerrors.As(err, &cmdErr) && cmdErr.Code == codeUnauthorized
+}
+
+// createUser creates cluster user with root role. Client relies on
+// localhost exception, so it is only allowed before first user exists.
+func (c *Cluster) createUser(ctx context.Context, client *mongo.Client) error {
+	return client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "createUser", Value: c.auth.Username},
+		{Key: "pwd", Value: c.auth.Password},
+		{Key: "roles", Value: bson.A{bson.M{"role": "root", "db": "admin"}}},
+	}).Err()
+}
+
+// createShardUser creates shard-local cluster user on primary of just
+// initiated shard replica set, so shard members are accessible directly.
+func (c *Cluster) createShardUser(ctx context.Context, shardID int) error {
+	ctx, cancel := context.WithTimeout(ctx, c.setupTimeout)
+	defer cancel()
+
+	// Credentials are not set: user does not exist yet.
+	uri := replicaSetURI(nil, c.shardReplicaSet(shardID), c.shardAddrs(shardID))
+	return withClient(ctx, uri, func(client *mongo.Client) error {
+		// Primary can step down right after election.
+		b := backoff.NewConstantBackOff(time.Millisecond * 500)
+		return backoff.Retry(func() error {
+			err := c.createUser(ctx, client)
+			if isUnauthorized(err) {
+				// Previous attempt created user, so localhost exception
+				// no longer applies.
+				return nil
+			}
+			return err
+		}, backoff.WithContext(b, ctx))
+	})
+}
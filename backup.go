@@ -0,0 +1,113 @@
+package booga
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// BackupFile describes file copied by BackupCursor.
+type BackupFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// nodePath resolves path reported by node, that is relative to working
+// directory of node process, i.e. node directory.
+func nodePath(nodeDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(nodeDir, path)
+}
+
+// backupFilePath returns path of file from dbpath in backup directory.
+// Both dbpath and name should be resolved by nodePath.
+func backupFilePath(dir, dbpath, name string) (string, error) {
+	rel, err := filepath.Rel(dbpath, name)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", xerrors.Errorf("%s is outside of %s", name, dbpath)
+	}
+	return filepath.Join(dir, rel), nil
+}
+
+// BackupCursor opens $backupCursor on node, copies every referenced file
+// to dir and closes the cursor, releasing checkpoint pinned by it.
+// Requires Enterprise or Percona Server for MongoDB.
+func (c *Cluster) BackupCursor(ctx context.Context, name, dir string) ([]BackupFile, error) {
+	uri, err := c.serviceURI(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []BackupFile
+	if err := withClient(ctx, uri, func(client *mongo.Client) error {
+		if err := requireDistribution(ctx, client, "backup cursor", Enterprise, Percona); err != nil {
+			return err
+		}
+		cur, err := client.Database("admin").Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$backupCursor", Value: bson.M{}}},
+		})
+		if err != nil {
+			return xerrors.Errorf("open: %w", err)
+		}
+		defer func() {
+			if err := cur.Close(ctx); err != nil {
+				c.log.Warn("Failed to close backup cursor", zap.Error(err))
+			}
+		}()
+
+		nodeDir := filepath.Join(c.dir, name)
+		var dbpath string
+		// Cursor is kept open by server after all files are returned, so
+		// iteration stops on first empty batch.
+		for cur.TryNext(ctx) {
+			var doc struct {
+				Metadata *struct {
+					DBPath string `bson:"dbpath"`
+				} `bson:"metadata"`
+				Filename string `bson:"filename"`
+				FileSize int64  `bson:"fileSize"`
+			}
+			if err := cur.Decode(&doc); err != nil {
+				return xerrors.Errorf("decode: %w", err)
+			}
+			if doc.Metadata != nil {
+				dbpath = nodePath(nodeDir, doc.Metadata.DBPath)
+				continue
+			}
+			if dbpath == "" {
+				return xerrors.New("no metadata")
+			}
+			src := nodePath(nodeDir, doc.Filename)
+			dst, err := backupFilePath(dir, dbpath, src)
+			if err != nil {
+				return err
+			}
+			if err := ensureDir(filepath.Dir(dst)); err != nil {
+				return xerrors.Errorf("ensure: %w", err)
+			}
+			if err := copyFile(dst, src); err != nil {
+				return xerrors.Errorf("copy %s: %w", doc.Filename, err)
+			}
+			files = append(files, BackupFile{Name: doc.Filename, Size: doc.FileSize})
+		}
+		if err := cur.Err(); err != nil {
+			return xerrors.Errorf("iterate: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
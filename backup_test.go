@@ -0,0 +1,46 @@
+package booga
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupFilePath(t *testing.T) {
+	dbpath := filepath.Join("data", "node")
+	got, err := backupFilePath("backup", dbpath, filepath.Join(dbpath, "journal", "WiredTigerLog.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("backup", "journal", "WiredTigerLog.1"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if _, err := backupFilePath("backup", dbpath, filepath.Join("data", "other", "file.wt")); err == nil {
+		t.Error("expected error for file outside of dbpath")
+	}
+}
+
+func TestBackupFilePathRelative(t *testing.T) {
+	// Node runs with "--dbpath ." in node directory, so reported paths
+	// are relative to it.
+	nodeDir := filepath.Join("cluster", "data-0-0")
+	dbpath := nodePath(nodeDir, ".")
+	src := nodePath(nodeDir, filepath.Join("journal", "WiredTigerLog.1"))
+	if want := filepath.Join(nodeDir, "journal", "WiredTigerLog.1"); src != want {
+		t.Errorf("got source %s, want %s", src, want)
+	}
+	got, err := backupFilePath("backup", dbpath, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("backup", "journal", "WiredTigerLog.1"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	abs, err := filepath.Abs("file.wt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nodePath(nodeDir, abs); got != abs {
+		t.Errorf("absolute path changed to %s", got)
+	}
+}
@@ -0,0 +1,123 @@
+package booga
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// Binary describes server binary used by cluster.
+type Binary struct {
+	Path         string       `json:"path"`    // resolved absolute path
+	Version      string       `json:"version"` // first line of --version output
+	Distribution Distribution `json:"distribution"`
+	SHA256       string       `json:"sha256"`
+}
+
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// inspectBinary resolves binary path, computes checksum and reads version.
+func inspectBinary(ctx context.Context, name string) (Binary, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return Binary{}, xerrors.Errorf("look path: %w", err)
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return Binary{}, xerrors.Errorf("abs: %w", err)
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return Binary{}, xerrors.Errorf("checksum: %w", err)
+	}
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return Binary{}, xerrors.Errorf("version: %w", err)
+	}
+	version, _ := bufio.NewReader(bytes.NewReader(out)).ReadString('\n')
+
+	return Binary{
+		Path:         path,
+		Version:      strings.TrimSpace(version),
+		Distribution: parseDistribution(string(out)),
+		SHA256:       sum,
+	}, nil
+}
+
+var versionRe = regexp.MustCompile(`v(\d+\.\d+(?:\.\d+)?)`)
+
+// binaryVersion returns version number from --version output line, e.g.
+// 7.0.2 from "db version v7.0.2".
+func binaryVersion(line string) string {
+	m := versionRe.FindStringSubmatch(line)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// versionMatches reports whether version equals expected or starts with
+// expected components, e.g. 7.0.2 matches 7 and 7.0, but not 7.0.1.
+func versionMatches(version, expected string) bool {
+	return version == expected || strings.HasPrefix(version, expected+".")
+}
+
+// verifyBinaries inspects every binary of cluster, records them to
+// startup report and checks configured checksums and version.
+func (c *Cluster) verifyBinaries(ctx context.Context) error {
+	expected := map[string]string{
+		c.mongod: c.mongodSHA256,
+		c.mongos: c.mongosSHA256,
+	}
+	names := []string{c.mongod, c.mongos}
+	for _, s := range c.shardSpecs {
+		if _, ok := expected[s.Mongod]; !ok {
+			expected[s.Mongod] = ""
+			names = append(names, s.Mongod)
+		}
+	}
+
+	for _, name := range names {
+		b, err := inspectBinary(ctx, name)
+		if err != nil {
+			return xerrors.Errorf("inspect %s: %w", name, err)
+		}
+		c.timeline.addBinary(b)
+		c.log.Info("Binary",
+			zap.String("path", b.Path),
+			zap.String("version", b.Version),
+			zap.Stringer("distribution", b.Distribution),
+			zap.String("sha256", b.SHA256),
+		)
+		if sum := expected[name]; sum != "" && !strings.EqualFold(sum, b.SHA256) {
+			return xerrors.Errorf("checksum mismatch of %s: expected %s, got %s", b.Path, sum, b.SHA256)
+		}
+		if v := binaryVersion(b.Version); c.version != "" && !versionMatches(v, c.version) {
+			return xerrors.Errorf("version mismatch of %s: expected %s, got %q", b.Path, c.version, b.Version)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package booga
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// Cache configures caching of fully seeded data directories.
+//
+// On first run, state of every stateful node is copied to cache after
+// OnSetup. Subsequent runs with same binary version, topology and Key
+// restore data directories from cache, skipping initialization, seeding
+// and OnSetup.
+type Cache struct {
+	// Dir is cache directory.
+	Dir string
+	// Key identifies fixtures, e.g. hash or version of seeding code.
+	// Should be changed every time OnSetup changes.
+	Key string
+}
+
+// cacheSkip is set of files that are not copied to or from cache.
+var cacheSkip = map[string]struct{}{
+	"mongod.lock":     {},
+	"diagnostic.data": {},
+}
+
+// copyDir recursively copies src directory to dst.
+func copyDir(dst, src string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if _, skip := cacheSkip[info.Name()]; skip {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+
+		return copyFile(target, path)
+	})
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// cacheKey returns key of cache entry for current configuration.
+func (c *Cluster) cacheKey(ctx context.Context) (string, error) {
+	version, err := exec.CommandContext(ctx, c.mongod, "--version").Output()
+	if err != nil {
+		return "", xerrors.Errorf("mongod version: %w", err)
+	}
+	routerVersion, err := exec.CommandContext(ctx, c.mongos, "--version").Output()
+	if err != nil {
+		return "", xerrors.Errorf("mongos version: %w", err)
+	}
+
+	h := sha256.New()
+	_, _ = h.Write(version)
+	_, _ = h.Write(routerVersion)
+	// Replica set configurations reference ports, so port offset is part
+	// of key.
+	_, _ = fmt.Fprintf(h, "\n%d/%d/%s/%v/%d\n", c.shards, c.replicas, c.db, c.maxCacheGB, c.portOffset)
+	_, _ = fmt.Fprintf(h, "naming:%+v\n", c.naming)
+	_, _ = fmt.Fprintf(h, "config:%d\n", c.configReplicas)
+	if c.auth != nil {
+		// Users are part of cached state.
+		_, _ = fmt.Fprintf(h, "auth:%s:%s\n", c.auth.Username, c.auth.Password)
+	}
+	for _, s := range c.shardSpecs {
+		_, _ = fmt.Fprintf(h, "shard:%+v\n", s)
+	}
+	for _, coll := range c.collections {
+		// Options are pointers, so creation command is hashed instead.
+		_, _ = fmt.Fprintf(h, "collection:%v/%v/%v\n", coll.createCommand(), coll.ShardKey, coll.Documents)
+	}
+	// Maps are printed with sorted keys.
+	_, _ = fmt.Fprintf(h, "parameters:%v\n", c.clusterParameters)
+	_, _ = fmt.Fprintf(h, "tags:%v\n", c.memberTags)
+	_, _ = fmt.Fprintf(h, "disks:%v\n", c.diskSizes)
+	_, _ = fmt.Fprintf(h, "majority:%v\n", c.majorityConcerns)
+	_, _ = fmt.Fprintf(h, "chunksize:%d/%v/%v\n", c.chunkSizeMB, c.noAutoSplit, c.noAutoMerge)
+	settings := map[string]bson.M{}
+	for name, s := range c.replicaSetSettings {
+		settings[name] = s.document()
+	}
+	_, _ = fmt.Fprintf(h, "settings:%v\n", settings)
+	for _, b := range c.gridFS {
+		_, _ = fmt.Fprintf(h, "gridfs:%s:%s\n", b.name(), b.Dir)
+		// Files are seeded, so changed file invalidates cache.
+		if err := b.writeDigest(h); err != nil {
+			return "", xerrors.Errorf("gridfs %s: %w", b.Dir, err)
+		}
+	}
+	_, _ = io.WriteString(h, c.cache.Key)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// restoreCache copies cached state to cluster directory if cache entry
+// exists.
+func (c *Cluster) restoreCache(ctx context.Context) error {
+	if c.cache == nil {
+		return nil
+	}
+
+	key, err := c.cacheKey(ctx)
+	if err != nil {
+		return xerrors.Errorf("key: %w", err)
+	}
+	c.cacheEntry = filepath.Join(c.cache.Dir, key)
+
+	if _, err := os.Stat(c.cacheEntry); os.IsNotExist(err) {
+		c.log.Info("Cache miss", zap.String("key", key))
+		return nil
+	} else if err != nil {
+		return xerrors.Errorf("stat: %w", err)
+	}
+
+	for _, n := range c.statefulNodes() {
+		if _, ok := c.diskSizes[n.Name]; ok {
+			// Data directory is restored after tmpfs is mounted to it,
+			// see runServer.
+			continue
+		}
+		if err := c.restoreNode(n.Name); err != nil {
+			return xerrors.Errorf("copy %s: %w", n.Name, err)
+		}
+	}
+	c.restored = true
+	c.log.Info("State restored from cache", zap.String("key", key))
+
+	return nil
+}
+
+// restoreNode copies cached data directory of node to cluster directory.
+func (c *Cluster) restoreNode(name string) error {
+	return copyDir(filepath.Join(c.dir, name), filepath.Join(c.cacheEntry, name))
+}
+
+// snapshotNode copies data directory of node to dst while node is locked
+// for writes.
+func (c *Cluster) snapshotNode(ctx context.Context, n node, dst string) error {
+	return withClient(ctx, directURI(c.userinfo(), n.Port), func(client *mongo.Client) error {
+		if err := fsyncLock(ctx, client); err != nil {
+			return err
+		}
+		copyErr := copyDir(dst, filepath.Join(c.dir, n.Name))
+		if err := fsyncUnlock(ctx, client); err != nil {
+			return err
+		}
+		if copyErr != nil {
+			return xerrors.Errorf("copy: %w", copyErr)
+		}
+
+		return nil
+	})
+}
+
+// saveCache copies state of every stateful node to cache.
+func (c *Cluster) saveCache(ctx context.Context) error {
+	if c.cache == nil {
+		return nil
+	}
+	if err := ensureDir(c.cache.Dir); err != nil {
+		return xerrors.Errorf("ensure: %w", err)
+	}
+
+	// Writing to temporary directory first, so concurrent or interrupted
+	// runs never observe partial entry.
+	tmp := c.cacheEntry + ".tmp-" + strconv.Itoa(os.Getpid())
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	for _, n := range c.statefulNodes() {
+		if err := c.snapshotNode(ctx, n, filepath.Join(tmp, n.Name)); err != nil {
+			return xerrors.Errorf("snapshot %s: %w", n.Name, err)
+		}
+	}
+	if err := os.Rename(tmp, c.cacheEntry); err != nil && !os.IsExist(err) {
+		return xerrors.Errorf("rename: %w", err)
+	}
+	c.log.Info("State saved to cache", zap.String("dir", c.cacheEntry))
+
+	return nil
+}
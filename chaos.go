@@ -0,0 +1,291 @@
+package booga
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// Fault is type of failure injected by Chaos.
+type Fault string
+
+// Supported faults.
+const (
+	// FaultKill kills service and starts it again on recovery.
+	FaultKill Fault = "kill"
+	// FaultRestart restarts service.
+	FaultRestart Fault = "restart"
+	// FaultPause stops service process and continues it on recovery.
+	FaultPause Fault = "pause"
+	// FaultPartition drops network traffic of service until recovery,
+	// including connections service opens, see Config.EnablePartitions.
+	FaultPartition Fault = "partition"
+	// FaultInboundPartition drops network traffic sent to service until
+	// recovery, so service still reaches other services, but they can't
+	// reach it. Requires iptables on linux.
+	FaultInboundPartition Fault = "partition-inbound"
+	// FaultStepDown steps down primary of target service replica set.
+	FaultStepDown Fault = "stepdown"
+	// FaultRecover recovers faults injected to service, only for
+	// Schedule.
+	FaultRecover Fault = "recover"
+)
+
+// ChaosOptions configures Chaos.
+type ChaosOptions struct {
+	// Seed of fault and target selection.
+	Seed int64
+	// Faults to inject, every fault except partitions by default if
+	// Nemeses are not set.
+	Faults []Fault
+	// Nemeses are custom faults by name, injected along with Faults.
+	Nemeses map[string]Nemesis
+	// Targets selects services to inject faults to, data servers by
+	// default.
+	Targets func(s ServiceInfo) bool
+	// Interval is mean interval between faults, 5s by default.
+	Interval time.Duration
+	// Duration of fault before recovery, 1s by default.
+	Duration time.Duration
+}
+
+// ChaosAction is fault injected by Chaos.
+type ChaosAction struct {
+	At     time.Duration // since start of Run
+	Fault  Fault
+	Target string
+	Err    error // injection error
+}
+
+// Chaos injects random faults to cluster services.
+type Chaos struct {
+	cluster *Cluster
+	opt     ChaosOptions
+	rand    *rand.Rand
+	nemeses []string // sorted names of ChaosOptions.Nemeses
+
+	mux     sync.Mutex
+	actions []ChaosAction
+}
+
+// Chaos returns fault injector for cluster.
+func (c *Cluster) Chaos(opt ChaosOptions) *Chaos {
+	if len(opt.Faults) == 0 && len(opt.Nemeses) == 0 {
+		opt.Faults = []Fault{FaultKill, FaultRestart, FaultPause, FaultStepDown}
+	}
+	if opt.Targets == nil {
+		opt.Targets = func(s ServiceInfo) bool { return s.Type == DataServer }
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = time.Second * 5
+	}
+	if opt.Duration <= 0 {
+		opt.Duration = time.Second
+	}
+
+	var nemeses []string
+	for name := range opt.Nemeses {
+		nemeses = append(nemeses, name)
+	}
+	sort.Strings(nemeses)
+
+	return &Chaos{
+		cluster: c,
+		opt:     opt,
+		rand:    rand.New(rand.NewSource(opt.Seed)),
+		nemeses: nemeses,
+	}
+}
+
+// Actions returns injected faults.
+func (ch *Chaos) Actions() []ChaosAction {
+	ch.mux.Lock()
+	defer ch.mux.Unlock()
+
+	return append([]ChaosAction(nil), ch.actions...)
+}
+
+// servicePort returns port of service.
+func servicePort(s ServiceInfo) (int, error) {
+	_, port, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(port)
+}
+
+// isNetworkError reports whether err is network error of command.
+func isNetworkError(err error) bool {
+	var cmdErr mongo.CommandError
+	return xerrors.As(err, &cmdErr) && cmdErr.HasErrorLabel("NetworkError")
+}
+
+// stepDown steps down primary of shard replica set.
+func (c *Cluster) stepDown(ctx context.Context, shardID int) error {
+	return withClient(ctx, c.shardURI(shardID), func(client *mongo.Client) error {
+		err := client.Database("admin").
+			RunCommand(ctx, bson.M{"replSetStepDown": 10, "force": true}).
+			Err()
+		if err != nil && !isNetworkError(err) {
+			// Old versions close connections on step down.
+			return xerrors.Errorf("replSetStepDown: %w", err)
+		}
+		return nil
+	})
+}
+
+// inject injects fault to service and returns function that recovers it.
+func (c *Cluster) inject(ctx context.Context, fault Fault, s ServiceInfo) (func(ctx context.Context) error, error) {
+	noop := func(ctx context.Context) error { return nil }
+	switch fault {
+	case FaultKill:
+		if err := c.Kill(s.Name); err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			if err := c.waitServiceState(ctx, s.Name, ServiceStopped); err != nil {
+				return err
+			}
+			return c.Restart(s.Name)
+		}, nil
+	case FaultRestart:
+		return noop, c.Restart(s.Name)
+	case FaultPause:
+		p, err := c.Process(s.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := pauseProcess(p); err != nil {
+			return nil, xerrors.Errorf("pause: %w", err)
+		}
+		return func(ctx context.Context) error { return resumeProcess(p) }, nil
+	case FaultPartition, FaultInboundPartition:
+		heal, err := c.isolate(s, fault == FaultInboundPartition)
+		if err != nil {
+			return nil, xerrors.Errorf("isolate: %w", err)
+		}
+		return func(ctx context.Context) error { return heal() }, nil
+	case FaultStepDown:
+		if s.Type != DataServer {
+			return nil, xerrors.Errorf("%s is not data server", s.Name)
+		}
+		return noop, c.stepDown(ctx, s.ShardID)
+	default:
+		return nil, xerrors.Errorf("unknown fault %q", fault)
+	}
+}
+
+// recoveryKey returns target of FaultRecover that recovers fault: service
+// name for built-in faults and nemesis name otherwise.
+func recoveryKey(fault Fault, target string) string {
+	if target == "" {
+		return string(fault)
+	}
+	return target
+}
+
+// record records action that happened at.
+func (ch *Chaos) record(at time.Duration, fault Fault, target string, err error) {
+	ch.mux.Lock()
+	defer ch.mux.Unlock()
+
+	ch.actions = append(ch.actions, ChaosAction{
+		At:     at,
+		Fault:  fault,
+		Target: target,
+		Err:    err,
+	})
+}
+
+// targets returns services that match ChaosOptions.Targets.
+func (ch *Chaos) targets() []ServiceInfo {
+	var targets []ServiceInfo
+	for _, s := range ch.cluster.Services() {
+		if ch.opt.Targets(s) {
+			targets = append(targets, s)
+		}
+	}
+	return targets
+}
+
+// sleep waits for d or context cancellation and reports whether d passed.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Run injects faults until context cancellation. Every fault is recovered
+// before Run returns.
+func (ch *Chaos) Run(ctx context.Context) error {
+	log := ch.cluster.log.Named("chaos")
+	start := time.Now()
+	for {
+		// Uniformly distributed in [Interval/2, Interval*3/2).
+		wait := ch.opt.Interval/2 + time.Duration(ch.rand.Int63n(int64(ch.opt.Interval)))
+		if !sleep(ctx, wait) {
+			return nil
+		}
+
+		var (
+			fault  Fault
+			target string
+			n      Nemesis
+		)
+		if i := ch.rand.Intn(len(ch.opt.Faults) + len(ch.nemeses)); i < len(ch.opt.Faults) {
+			targets := ch.targets()
+			if len(targets) == 0 {
+				log.Warn("No targets")
+				continue
+			}
+			s := targets[ch.rand.Intn(len(targets))]
+			fault, target = ch.opt.Faults[i], s.Name
+			n = &faultNemesis{fault: fault, target: s}
+		} else {
+			name := ch.nemeses[i-len(ch.opt.Faults)]
+			fault, n = Fault(name), ch.opt.Nemeses[name]
+		}
+
+		log.Info("Injecting fault",
+			zap.String("fault", string(fault)),
+			zap.String("target", target),
+		)
+		err := n.Inject(ctx, ch.cluster)
+		ch.record(time.Since(start), fault, target, err)
+		if err != nil {
+			log.Warn("Failed to inject fault", zap.Error(err))
+			continue
+		}
+
+		sleep(ctx, ch.opt.Duration)
+
+		// Recovering even if context is canceled, so cluster is not left
+		// broken.
+		recoverCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		err = n.Recover(recoverCtx, ch.cluster)
+		cancel()
+		ch.record(time.Since(start), FaultRecover, recoveryKey(fault, target), err)
+		if err != nil {
+			return xerrors.Errorf("recover %s: %w", fault, err)
+		}
+		log.Info("Fault recovered",
+			zap.String("fault", string(fault)),
+			zap.String("target", target),
+		)
+	}
+}
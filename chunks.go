@@ -0,0 +1,130 @@
+package booga
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/xerrors"
+)
+
+// maxChunkSizeMB is maximum chunk size accepted by config servers.
+const maxChunkSizeMB = 1024
+
+// setSetting sets fields of config.settings document with given id.
+func setSetting(ctx context.Context, client *mongo.Client, id string, fields bson.M) error {
+	if _, err := client.Database("config").Collection("settings").UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": fields},
+		options.Update().SetUpsert(true),
+	); err != nil {
+		return xerrors.Errorf("update %s: %w", id, err)
+	}
+	return nil
+}
+
+// setChunkSize sets default chunk size of cluster in config.settings.
+//
+// See https://docs.mongodb.com/manual/tutorial/modify-chunk-size-in-sharded-cluster/
+func setChunkSize(ctx context.Context, client *mongo.Client, sizeMB int) error {
+	return setSetting(ctx, client, "chunksize", bson.M{"value": sizeMB})
+}
+
+// SetChunkSize sets default chunk size of cluster in megabytes, from 1 to
+// 1024. Small chunks make splits and migrations observable with little
+// data.
+func (c *Cluster) SetChunkSize(ctx context.Context, sizeMB int) error {
+	if sizeMB < 1 || sizeMB > maxChunkSizeMB {
+		return xerrors.Errorf("chunk size %dMB is out of range from 1 to %d", sizeMB, maxChunkSizeMB)
+	}
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		return setChunkSize(ctx, client, sizeMB)
+	})
+}
+
+// SetAutoSplit enables or disables automatic splitting of chunks on
+// inserts and updates. Chunks are split by balancer since 6.0, so it has
+// no effect there.
+func (c *Cluster) SetAutoSplit(ctx context.Context, enabled bool) error {
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		return setSetting(ctx, client, "autosplit", bson.M{"enabled": enabled})
+	})
+}
+
+// SetAutoMerge enables or disables automatic merging of contiguous chunks
+// on the same shard. Requires 7.0.
+func (c *Cluster) SetAutoMerge(ctx context.Context, enabled bool) error {
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		return setSetting(ctx, client, "automerge", bson.M{"enabled": enabled})
+	})
+}
+
+// SetCollectionChunkSize sets chunk size of collection of cluster database
+// in megabytes, overriding default chunk size. Requires 6.0.
+func (c *Cluster) SetCollectionChunkSize(ctx context.Context, collection string, sizeMB int) error {
+	if sizeMB < 1 || sizeMB > maxChunkSizeMB {
+		return xerrors.Errorf("chunk size %dMB is out of range from 1 to %d", sizeMB, maxChunkSizeMB)
+	}
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		if err := client.Database("admin").RunCommand(ctx, bson.D{
+			{Key: "configureCollectionBalancing", Value: c.db + "." + collection},
+			{Key: "chunkSize", Value: sizeMB},
+		}).Err(); err != nil {
+			return xerrors.Errorf("configureCollectionBalancing: %w", err)
+		}
+		return nil
+	})
+}
+
+// chunkCommand runs chunk management command on router.
+func (c *Cluster) chunkCommand(ctx context.Context, cmd bson.D) error {
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		if err := client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+			return xerrors.Errorf("%s: %w", cmd[0].Key, err)
+		}
+		return nil
+	})
+}
+
+// MoveChunk moves chunk of collection of cluster database that contains
+// document matching find to shard, waiting for deletion of documents on
+// donor shard.
+func (c *Cluster) MoveChunk(ctx context.Context, collection string, find bson.D, shardID int) error {
+	if shardID < 0 || shardID >= c.shards {
+		return xerrors.Errorf("no shard %d", shardID)
+	}
+	return c.chunkCommand(ctx, bson.D{
+		{Key: "moveChunk", Value: c.db + "." + collection},
+		{Key: "find", Value: find},
+		{Key: "to", Value: c.shardReplicaSet(shardID)},
+		{Key: "_waitForDelete", Value: true},
+	})
+}
+
+// SplitAt splits chunk of collection of cluster database at shard key
+// value, so middle becomes lower bound of new chunk.
+func (c *Cluster) SplitAt(ctx context.Context, collection string, middle bson.D) error {
+	return c.chunkCommand(ctx, bson.D{
+		{Key: "split", Value: c.db + "." + collection},
+		{Key: "middle", Value: middle},
+	})
+}
+
+// SplitFind splits chunk of collection of cluster database that contains
+// document matching find at median point.
+func (c *Cluster) SplitFind(ctx context.Context, collection string, find bson.D) error {
+	return c.chunkCommand(ctx, bson.D{
+		{Key: "split", Value: c.db + "." + collection},
+		{Key: "find", Value: find},
+	})
+}
+
+// MergeChunks merges contiguous chunks of collection of cluster database
+// on the same shard that cover range from min to max shard key value.
+func (c *Cluster) MergeChunks(ctx context.Context, collection string, min, max bson.D) error {
+	return c.chunkCommand(ctx, bson.D{
+		{Key: "mergeChunks", Value: c.db + "." + collection},
+		{Key: "bounds", Value: bson.A{min, max}},
+	})
+}
@@ -0,0 +1,393 @@
+package booga
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// Default ports, shifted by Cluster.portOffset. Config servers and router
+// are below data range, so data ports never reach them.
+const (
+	configPort  = 28001
+	routingPort = 28501
+	dataPort    = 29000
+)
+
+// maxConfigReplicas is limit of config server replica set members, that
+// are all voting.
+const maxConfigReplicas = 7
+
+func (c *Cluster) configPort(id int) int {
+	return c.portOffset + configPort + id
+}
+
+func (c *Cluster) routingPort() int {
+	return c.portOffset + routingPort
+}
+
+func (c *Cluster) dataPort(shardID, id int) int {
+	return c.portOffset + dataPort + shardID*100 + id
+}
+
+// maxShards returns limit of shards, so ports of members stay within
+// port range of slot if coordinate is set, or below 65536 otherwise.
+func maxShards(coordinate bool) int {
+	last := 65535
+	if coordinate {
+		// Port range of slot starts right below config port.
+		last = configPort - 1 + slotPorts - 1
+	}
+	return (last - dataPort + 1) / maxMembers
+}
+
+// localAddr returns loopback address for given port.
+func localAddr(port int) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+}
+
+// node is stateful cluster member.
+type node struct {
+	Name string
+	Port int
+}
+
+// statefulNodes returns every config server and shard member.
+func (c *Cluster) statefulNodes() []node {
+	var nodes []node
+	for id := 0; id < c.configReplicas; id++ {
+		nodes = append(nodes, node{Name: c.configMemberName(id), Port: c.configPort(id)})
+	}
+	for shardID := 0; shardID < c.shards; shardID++ {
+		for _, id := range c.shardMembers(shardID) {
+			nodes = append(nodes, node{
+				Name: c.dataName(shardID, id),
+				Port: c.dataPort(shardID, id),
+			})
+		}
+	}
+	return nodes
+}
+
+// directURI returns connection string for direct connection to node with
+// optional credentials.
+func directURI(user *url.Userinfo, port int) string {
+	u := &url.URL{
+		Scheme:   "mongodb",
+		User:     user,
+		Host:     localAddr(port),
+		Path:     "/",
+		RawQuery: "directConnection=true",
+	}
+	return u.String()
+}
+
+// configAddrs returns addresses of every config server.
+func (c *Cluster) configAddrs() []string {
+	addrs := make([]string, 0, c.configReplicas)
+	for id := 0; id < c.configReplicas; id++ {
+		addrs = append(addrs, localAddr(c.configPort(id)))
+	}
+	return addrs
+}
+
+// shardAddrs returns addresses of every replica set member of shard.
+func (c *Cluster) shardAddrs(shardID int) []string {
+	var addrs []string
+	for _, id := range c.shardMembers(shardID) {
+		addrs = append(addrs, localAddr(c.dataPort(shardID, id)))
+	}
+	return addrs
+}
+
+// ReplicaSet describes replica set of cluster.
+type ReplicaSet struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"` // host:port
+}
+
+// Topology describes cluster members.
+type Topology struct {
+	ConfigServer ReplicaSet   `json:"config_server"`
+	Shards       []ReplicaSet `json:"shards"`
+	Routers      []string     `json:"routers"` // host:port
+}
+
+// Topology returns description of cluster members.
+func (c *Cluster) Topology() Topology {
+	if c.ferretDB != "" {
+		// Single FerretDB service.
+		return Topology{Routers: []string{localAddr(c.routingPort())}}
+	}
+
+	t := Topology{
+		ConfigServer: ReplicaSet{
+			Name:    c.configReplicaSet(),
+			Members: c.configAddrs(),
+		},
+		Routers: []string{localAddr(c.routingPort())},
+	}
+	for shardID := 0; shardID < c.shards; shardID++ {
+		t.Shards = append(t.Shards, ReplicaSet{
+			Name:    c.shardReplicaSet(shardID),
+			Members: c.shardAddrs(shardID),
+		})
+	}
+	return t
+}
+
+// replicaSetURI returns connection string for replica set with optional
+// credentials.
+func replicaSetURI(user *url.Userinfo, name string, addrs []string) string {
+	u := &url.URL{
+		Scheme:   "mongodb",
+		User:     user,
+		Host:     strings.Join(addrs, ","),
+		Path:     "/",
+		RawQuery: url.Values{"replicaSet": []string{name}}.Encode(),
+	}
+	return u.String()
+}
+
+// shardURI returns replica set aware connection string for shard.
+func (c *Cluster) shardURI(shardID int) string {
+	return replicaSetURI(c.userinfo(), c.shardReplicaSet(shardID), c.shardAddrs(shardID))
+}
+
+// routerURI returns connection string for routing server.
+func (c *Cluster) routerURI() string {
+	u := &url.URL{
+		Scheme: "mongodb",
+		User:   c.userinfo(),
+		Host:   localAddr(c.routingPort()),
+		Path:   "/",
+	}
+	return u.String()
+}
+
+// ConfigServerURI returns replica set aware connection string of config
+// server replica set.
+func (c *Cluster) ConfigServerURI() string {
+	return replicaSetURI(c.userinfo(), c.configReplicaSet(), c.configAddrs())
+}
+
+// ShardURI returns replica set aware connection string of shard with
+// current members.
+func (c *Cluster) ShardURI(shardID int) (string, error) {
+	if shardID < 0 || shardID >= c.shards {
+		return "", xerrors.Errorf("no shard %d", shardID)
+	}
+	return c.shardURI(shardID), nil
+}
+
+// RouterURIs returns connection strings of every routing server.
+func (c *Cluster) RouterURIs() []string {
+	return []string{c.routerURI()}
+}
+
+// AnalyticsClient returns new client connected to routing server that
+// reads from analytics members, see ShardSpec.Analytics.
+//
+// Client should be disconnected by caller.
+func (c *Cluster) AnalyticsClient(ctx context.Context) (*mongo.Client, error) {
+	return connect(ctx, c.routerURI(), options.Client().SetReadPreference(
+		readpref.Secondary(readpref.WithTags(AnalyticsTagKey, AnalyticsTagValue)),
+	))
+}
+
+// ConfigClient returns new client connected to config server replica
+// set, e.g. to read config.chunks or config.shards directly.
+//
+// Client should be disconnected by caller.
+func (c *Cluster) ConfigClient(ctx context.Context) (*mongo.Client, error) {
+	if c.ferretDB != "" {
+		return nil, xerrors.New("no config server with FerretDB")
+	}
+	return connect(ctx, c.ConfigServerURI())
+}
+
+// RouterClient returns new client connected to routing server.
+//
+// Client should be disconnected by caller.
+func (c *Cluster) RouterClient(ctx context.Context) (*mongo.Client, error) {
+	return connect(ctx, c.routerURI())
+}
+
+// connect connects to given uri and returns client that should be
+// disconnected by caller.
+func connect(ctx context.Context, uri string, opts ...*options.ClientOptions) (*mongo.Client, error) {
+	opts = append([]*options.ClientOptions{options.Client().ApplyURI(uri)}, opts...)
+	client, err := mongo.Connect(ctx, opts...)
+	if err != nil {
+		return nil, xerrors.Errorf("connect: %w", err)
+	}
+	return client, nil
+}
+
+// withClient connects to uri and calls f with connected client.
+func withClient(ctx context.Context, uri string, f func(client *mongo.Client) error) error {
+	client, err := connect(ctx, uri)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Disconnect(ctx)
+	}()
+
+	return f(client)
+}
+
+// codeCommandNotFound is server error code of unknown command.
+const codeCommandNotFound = 59
+
+// helloReply is role of replica set member from hello.
+type helloReply struct {
+	IsWritablePrimary bool `bson:"isWritablePrimary"`
+	IsMaster          bool `bson:"ismaster"` // before 4.4.2
+	Secondary         bool `bson:"secondary"`
+	// ElectionID is reported by primary and grows with election term.
+	ElectionID primitive.ObjectID `bson:"electionId"`
+}
+
+// hello returns role of member, falling back to isMaster on servers
+// without hello.
+func hello(ctx context.Context, client *mongo.Client) (helloReply, error) {
+	var reply helloReply
+	admin := client.Database("admin")
+	err := admin.RunCommand(ctx, bson.M{"hello": 1}).Decode(&reply)
+	if cmdErr, ok := err.(mongo.CommandError); ok && cmdErr.Code == codeCommandNotFound {
+		err = admin.RunCommand(ctx, bson.M{"isMaster": 1}).Decode(&reply)
+	}
+	if err != nil {
+		return reply, err
+	}
+	reply.IsWritablePrimary = reply.IsWritablePrimary || reply.IsMaster
+	return reply, nil
+}
+
+// memberSelectionTimeout is server selection timeout of member client, so
+// unreachable member, e.g. partitioned one, is skipped quickly.
+const memberSelectionTimeout = time.Second * 2
+
+// memberClient is direct connection to replica set member.
+type memberClient struct {
+	name   string
+	client *mongo.Client
+	hello  helloReply
+}
+
+// memberClients returns direct connections to ready members of shard with
+// role matching f. Members that are not ready or not reachable are
+// skipped and reported by returned error, along with connections to
+// other members.
+func (c *Cluster) memberClients(ctx context.Context, shardID int, f func(r helloReply) bool) ([]memberClient, error) {
+	var (
+		members []memberClient
+		errs    error
+	)
+	for _, id := range c.shardMembers(shardID) {
+		name := c.dataName(shardID, id)
+		state := ServiceStopped
+		c.services.with(name, func(s *service) { state = s.info.State })
+		if state != ServiceReady {
+			errs = multierr.Append(errs, xerrors.Errorf("%s is %s", name, state))
+			continue
+		}
+
+		client, err := connect(ctx, directURI(c.userinfo(), c.dataPort(shardID, id)),
+			options.Client().SetServerSelectionTimeout(memberSelectionTimeout),
+		)
+		if err != nil {
+			errs = multierr.Append(errs, xerrors.Errorf("%s: %w", name, err))
+			continue
+		}
+		reply, err := hello(ctx, client)
+		if err != nil {
+			_ = client.Disconnect(ctx)
+			errs = multierr.Append(errs, xerrors.Errorf("%s: hello: %w", name, err))
+			continue
+		}
+		if !f(reply) {
+			_ = client.Disconnect(ctx)
+			continue
+		}
+		members = append(members, memberClient{name: name, client: client, hello: reply})
+	}
+	return members, errs
+}
+
+// PrimaryClient returns new client directly connected to current primary
+// of shard. If several members report themselves as primary, e.g. stale
+// primary during partition, primary of latest election is selected.
+//
+// Client should be disconnected by caller.
+func (c *Cluster) PrimaryClient(ctx context.Context, shardID int) (*mongo.Client, error) {
+	if shardID < 0 || shardID >= c.shards {
+		return nil, xerrors.Errorf("no shard %d", shardID)
+	}
+	members, err := c.memberClients(ctx, shardID, func(r helloReply) bool {
+		return r.IsWritablePrimary
+	})
+	if len(members) == 0 {
+		if err != nil {
+			return nil, xerrors.Errorf("no primary of %s: %w", c.shardReplicaSet(shardID), err)
+		}
+		return nil, xerrors.Errorf("no primary of %s", c.shardReplicaSet(shardID))
+	}
+
+	primary := latestPrimary(members)
+	for _, m := range members {
+		if m.name != primary.name {
+			_ = m.client.Disconnect(ctx)
+		}
+	}
+	return primary.client, nil
+}
+
+// latestPrimary returns member that reports greatest election id.
+func latestPrimary(members []memberClient) memberClient {
+	latest := members[0]
+	for _, m := range members[1:] {
+		if bytes.Compare(m.hello.ElectionID[:], latest.hello.ElectionID[:]) > 0 {
+			latest = m
+		}
+	}
+	return latest
+}
+
+// SecondaryClients returns new clients directly connected to every ready
+// and reachable secondary of shard. Skipped members are logged.
+//
+// Clients should be disconnected by caller.
+func (c *Cluster) SecondaryClients(ctx context.Context, shardID int) ([]*mongo.Client, error) {
+	if shardID < 0 || shardID >= c.shards {
+		return nil, xerrors.Errorf("no shard %d", shardID)
+	}
+	members, err := c.memberClients(ctx, shardID, func(r helloReply) bool {
+		return r.Secondary
+	})
+	if err != nil {
+		c.log.Warn("Skipped members of shard",
+			zap.String("rs", c.shardReplicaSet(shardID)),
+			zap.Error(err),
+		)
+	}
+	clients := make([]*mongo.Client, 0, len(members))
+	for _, m := range members {
+		clients = append(clients, m.client)
+	}
+	return clients, nil
+}
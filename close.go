@@ -0,0 +1,178 @@
+package booga
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// Retention is policy of keeping data directories of services.
+type Retention byte
+
+const (
+	// RetainNone removes data directory when service stops running with
+	// cluster. Directory is kept while service is killed by Kill, so
+	// Restart reuses its data.
+	RetainNone Retention = iota
+	// RetainOnFailure keeps data directories on Close if cluster failed
+	// or teardown was not clean, which is useful for debugging.
+	RetainOnFailure
+	// RetainAlways never removes data directories.
+	RetainAlways
+)
+
+// closeTimeout returns timeout of graceful shutdown in Close, that is
+// enough for every shutdown stage to use its budget.
+func (c *Cluster) closeTimeout() time.Duration {
+	return stopStageTimeout * time.Duration(len(c.shutdownStages()))
+}
+
+// Close stops every service and removes data directories according to
+// retention policy. Errors of every service are aggregated.
+//
+// Close is safe to call multiple times, only first call does teardown.
+func (c *Cluster) Close() error {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.close()
+	})
+	return c.closeErr
+}
+
+// waitStopped waits until every service process exits.
+func (c *Cluster) waitStopped(ctx context.Context) error {
+	ticker := time.NewTicker(time.Millisecond * 50)
+	defer ticker.Stop()
+
+	for {
+		running := false
+		for _, s := range c.Services() {
+			if s.State != ServiceStopped && s.PID != 0 {
+				running = true
+			}
+		}
+		if !running {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// kill kills process group of every running service.
+func (c *Cluster) kill() {
+	c.services.each(func(s *service) {
+		if s.process != nil {
+			_ = signalGroup(s.process, syscall.SIGKILL)
+		}
+	})
+}
+
+// reapTimeout is timeout of waiting for killed processes to exit.
+const reapTimeout = time.Second * 10
+
+// runningPIDs returns pids of every running service.
+func (c *Cluster) runningPIDs() []int {
+	var pids []int
+	for _, s := range c.Services() {
+		if s.State != ServiceStopped && s.PID != 0 {
+			pids = append(pids, s.PID)
+		}
+	}
+	return pids
+}
+
+// reap kills every process that is still running, waits for exit and
+// removes data directories, so no stray process or state is left after
+// partially started cluster failed.
+func (c *Cluster) reap() error {
+	c.kill()
+
+	ctx, cancel := context.WithTimeout(context.Background(), reapTimeout)
+	defer cancel()
+	if err := c.waitStopped(ctx); err != nil {
+		return xerrors.Errorf("processes %v are still running: %w", c.runningPIDs(), err)
+	}
+
+	if c.retention != RetainNone {
+		return nil
+	}
+
+	return c.removeState()
+}
+
+// removeState removes data directories of every stateful service.
+func (c *Cluster) removeState() error {
+	var errs error
+	for _, n := range c.statefulNodes() {
+		if err := os.RemoveAll(filepath.Join(c.dir, n.Name)); err != nil {
+			errs = multierr.Append(errs, xerrors.Errorf("remove %s: %w", n.Name, err))
+		}
+	}
+
+	return errs
+}
+
+func (c *Cluster) close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.closeTimeout())
+	defer cancel()
+
+	// Only services that are running now can fail on teardown.
+	var running []string
+	for _, s := range c.Services() {
+		if s.State != ServiceStopped && s.PID != 0 {
+			running = append(running, s.Name)
+		}
+	}
+
+	var errs error
+	c.lifecycle.mux.Lock()
+	started := c.lifecycle.started
+	c.lifecycle.mux.Unlock()
+	if started {
+		if err := c.Stop(ctx); err != nil {
+			errs = multierr.Append(errs, xerrors.Errorf("stop: %w", err))
+		}
+	} else {
+		err := c.terminate(ctx)
+		if err == nil {
+			err = c.waitStopped(ctx)
+		}
+		if err != nil {
+			c.kill()
+			errs = multierr.Append(errs, xerrors.Errorf("wait: %w", err))
+		}
+	}
+
+	for _, name := range running {
+		c.services.with(name, func(s *service) {
+			if s.exitErr != nil {
+				errs = multierr.Append(errs, xerrors.Errorf("%s: %w", name, s.exitErr))
+			}
+		})
+	}
+
+	if c.detached {
+		if err := os.Remove(c.statePath()); err != nil && !os.IsNotExist(err) {
+			errs = multierr.Append(errs, xerrors.Errorf("remove state: %w", err))
+		}
+	}
+
+	remove := c.retention == RetainNone ||
+		(c.retention == RetainOnFailure && errs == nil && c.lifecycle.err == nil)
+	if !remove {
+		c.log.Info("Data directories retained", zap.String("dir", c.dir))
+		return errs
+	}
+
+	return multierr.Append(errs, c.removeState())
+}
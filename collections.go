@@ -0,0 +1,186 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// Collection describes collection that is created in cluster database
+// before OnSetup is called.
+type Collection struct {
+	Name string
+
+	// ShardKey enables sharding of collection if set.
+	ShardKey bson.D
+
+	// TimeSeries makes collection time-series collection, requires
+	// MongoDB 5.0, or 5.1 if ShardKey is set.
+	TimeSeries *TimeSeries
+
+	// Clustered makes collection clustered by _id, requires MongoDB 5.3.
+	// Collection is created clustered before sharding, because clustered
+	// index can't be added later.
+	Clustered bool
+
+	// Capped makes collection capped, e.g. for tailable cursors. Capped
+	// collections can't be sharded.
+	Capped *Capped
+
+	// Documents are inserted to collection after creation.
+	Documents []interface{}
+
+	// ViewOn makes collection read-only view on collection or view with
+	// that name, defined by Pipeline. Views are created after other
+	// collections and can't have other options.
+	ViewOn   string
+	Pipeline mongo.Pipeline
+}
+
+// Capped options of collection.
+//
+// See https://docs.mongodb.com/manual/core/capped-collections/
+type Capped struct {
+	Size int64 // maximum size in bytes
+	Max  int64 // maximum count of documents, optional
+}
+
+// TimeSeries options of collection.
+//
+// See https://docs.mongodb.com/manual/core/timeseries-collections/
+type TimeSeries struct {
+	TimeField string
+	MetaField string // optional
+	// Granularity is "seconds", "minutes" or "hours", optional.
+	Granularity string
+	// ExpireAfter enables automatic removal of documents, optional.
+	// Rounded up to seconds.
+	ExpireAfter time.Duration
+}
+
+func (t *TimeSeries) options() bson.D {
+	opt := bson.D{{Key: "timeField", Value: t.TimeField}}
+	if t.MetaField != "" {
+		opt = append(opt, bson.E{Key: "metaField", Value: t.MetaField})
+	}
+	if t.Granularity != "" {
+		opt = append(opt, bson.E{Key: "granularity", Value: t.Granularity})
+	}
+	return opt
+}
+
+// createCommand returns "create" command for collection.
+func (coll Collection) createCommand() bson.D {
+	cmd := bson.D{{Key: "create", Value: coll.Name}}
+	if coll.ViewOn != "" {
+		pipeline := coll.Pipeline
+		if pipeline == nil {
+			pipeline = mongo.Pipeline{}
+		}
+		return append(cmd,
+			bson.E{Key: "viewOn", Value: coll.ViewOn},
+			bson.E{Key: "pipeline", Value: pipeline},
+		)
+	}
+	if ts := coll.TimeSeries; ts != nil {
+		cmd = append(cmd, bson.E{Key: "timeseries", Value: ts.options()})
+		if ts.ExpireAfter > 0 {
+			// Sub-second expiration would be truncated to zero, that is
+			// rejected by server.
+			expire := int64((ts.ExpireAfter + time.Second - 1) / time.Second)
+			cmd = append(cmd, bson.E{Key: "expireAfterSeconds", Value: expire})
+		}
+	}
+	if cp := coll.Capped; cp != nil {
+		cmd = append(cmd,
+			bson.E{Key: "capped", Value: true},
+			bson.E{Key: "size", Value: cp.Size},
+		)
+		if cp.Max > 0 {
+			cmd = append(cmd, bson.E{Key: "max", Value: cp.Max})
+		}
+	}
+	if coll.Clustered {
+		cmd = append(cmd, bson.E{Key: "clusteredIndex", Value: bson.D{
+			{Key: "key", Value: bson.D{{Key: "_id", Value: 1}}},
+			{Key: "unique", Value: true},
+		}})
+	}
+	return cmd
+}
+
+// validate returns problems of collection configuration.
+func (coll Collection) validate() []string {
+	var problems []string
+	if coll.Name == "" {
+		problems = append(problems, "collection name is empty")
+	}
+	if coll.Clustered && coll.TimeSeries != nil {
+		problems = append(problems, fmt.Sprintf("collection %q is clustered and time-series: time-series collections are clustered implicitly, unset Clustered", coll.Name))
+	}
+	if coll.ViewOn != "" && (len(coll.ShardKey) > 0 || coll.TimeSeries != nil ||
+		coll.Clustered || coll.Capped != nil || len(coll.Documents) > 0) {
+		problems = append(problems, fmt.Sprintf("collection %q is view: unset ShardKey, TimeSeries, Clustered, Capped and Documents", coll.Name))
+	}
+	if ts := coll.TimeSeries; ts != nil && ts.ExpireAfter < 0 {
+		problems = append(problems, fmt.Sprintf("collection %q has ExpireAfter %s: set positive duration or zero to keep documents", coll.Name, ts.ExpireAfter))
+	}
+	if cp := coll.Capped; cp != nil {
+		if cp.Size <= 0 {
+			problems = append(problems, fmt.Sprintf("collection %q has capped size %d: set positive size in bytes", coll.Name, cp.Size))
+		}
+		if len(coll.ShardKey) > 0 || coll.Clustered || coll.TimeSeries != nil {
+			problems = append(problems, fmt.Sprintf("collection %q is capped: unset ShardKey, Clustered and TimeSeries", coll.Name))
+		}
+	}
+	return problems
+}
+
+// setupCollections creates collections from configuration.
+func (c *Cluster) setupCollections(ctx context.Context, client *mongo.Client) error {
+	db := client.Database(c.db)
+	var views []Collection
+	for _, coll := range c.collections {
+		if coll.ViewOn != "" {
+			// Views are created after collections they are defined on.
+			views = append(views, coll)
+			continue
+		}
+		if err := db.RunCommand(ctx, coll.createCommand()).Err(); err != nil {
+			return xerrors.Errorf("create %s: %w", coll.Name, err)
+		}
+		if len(coll.ShardKey) > 0 && c.ferretDB == "" {
+			if err := client.Database("admin").RunCommand(ctx, bson.D{
+				{Key: "shardCollection", Value: db.Name() + "." + coll.Name},
+				{Key: "key", Value: coll.ShardKey},
+			}).Err(); err != nil {
+				return xerrors.Errorf("shard %s: %w", coll.Name, err)
+			}
+		}
+		if len(coll.Documents) > 0 {
+			if _, err := db.Collection(coll.Name).InsertMany(ctx, coll.Documents); err != nil {
+				return xerrors.Errorf("insert to %s: %w", coll.Name, err)
+			}
+		}
+		c.log.Info("Collection created",
+			zap.String("name", coll.Name),
+			zap.Bool("sharded", len(coll.ShardKey) > 0 && c.ferretDB == ""),
+		)
+	}
+	for _, view := range views {
+		if err := db.RunCommand(ctx, view.createCommand()).Err(); err != nil {
+			return xerrors.Errorf("create view %s: %w", view.Name, err)
+		}
+		c.log.Info("View created",
+			zap.String("name", view.Name),
+			zap.String("view_on", view.ViewOn),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCollectionCreateCommand(t *testing.T) {
+	got := Collection{Name: "events", Clustered: true}.createCommand()
+	expected := bson.D{
+		{Key: "create", Value: "events"},
+		{Key: "clusteredIndex", Value: bson.D{
+			{Key: "key", Value: bson.D{{Key: "_id", Value: 1}}},
+			{Key: "unique", Value: true},
+		}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("clustered: %v", got)
+	}
+
+	coll := Collection{
+		Name:       "metrics",
+		Clustered:  true,
+		TimeSeries: &TimeSeries{TimeField: "ts", ExpireAfter: time.Hour},
+	}
+	if problems := coll.validate(); len(problems) != 1 {
+		t.Errorf("unexpected problems %v", problems)
+	}
+}
+
+func TestCollectionTimeSeries(t *testing.T) {
+	coll := Collection{
+		Name: "metrics",
+		TimeSeries: &TimeSeries{
+			TimeField:   "ts",
+			MetaField:   "host",
+			Granularity: "seconds",
+			ExpireAfter: time.Minute + time.Millisecond*500,
+		},
+	}
+	expected := bson.D{
+		{Key: "create", Value: "metrics"},
+		{Key: "timeseries", Value: bson.D{
+			{Key: "timeField", Value: "ts"},
+			{Key: "metaField", Value: "host"},
+			{Key: "granularity", Value: "seconds"},
+		}},
+		{Key: "expireAfterSeconds", Value: int64(61)},
+	}
+	if got := coll.createCommand(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("time-series: %v", got)
+	}
+
+	// Sub-second expiration is not truncated to zero.
+	coll.TimeSeries.ExpireAfter = time.Millisecond * 100
+	if got := coll.createCommand(); !reflect.DeepEqual(got[len(got)-1], bson.E{Key: "expireAfterSeconds", Value: int64(1)}) {
+		t.Errorf("sub-second: %v", got)
+	}
+
+	coll.TimeSeries.ExpireAfter = -time.Second
+	if problems := coll.validate(); len(problems) != 1 {
+		t.Errorf("unexpected problems %v", problems)
+	}
+}
+
+func TestCollectionCapped(t *testing.T) {
+	got := Collection{Name: "log", Capped: &Capped{Size: 4096, Max: 10}}.createCommand()
+	expected := bson.D{
+		{Key: "create", Value: "log"},
+		{Key: "capped", Value: true},
+		{Key: "size", Value: int64(4096)},
+		{Key: "max", Value: int64(10)},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("capped: %v", got)
+	}
+
+	coll := Collection{
+		Name:     "log",
+		Capped:   &Capped{},
+		ShardKey: bson.D{{Key: "_id", Value: 1}},
+	}
+	if problems := coll.validate(); len(problems) != 2 {
+		t.Errorf("unexpected problems %v", problems)
+	}
+}
+
+func TestCollectionView(t *testing.T) {
+	view := Collection{
+		Name:     "active",
+		ViewOn:   "users",
+		Pipeline: mongo.Pipeline{{{Key: "$match", Value: bson.M{"active": true}}}},
+	}
+	got := view.createCommand()
+	expected := bson.D{
+		{Key: "create", Value: "active"},
+		{Key: "viewOn", Value: "users"},
+		{Key: "pipeline", Value: view.Pipeline},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("view: %v", got)
+	}
+
+	view.Capped = &Capped{Size: 1024}
+	if problems := view.validate(); len(problems) != 1 {
+		t.Errorf("unexpected problems %v", problems)
+	}
+}
@@ -0,0 +1,180 @@
+package booga
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minCacheGB is minimum WiredTiger cache size accepted by mongod.
+const minCacheGB = 0.25
+
+// ConfigError lists every problem of Config with suggested fix.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid config:\n\t" + strings.Join(e.Problems, "\n\t")
+}
+
+// defaultDB is name of cluster database if Config.DB is empty.
+const defaultDB = "cloud"
+
+func (opt Config) db() string {
+	if opt.DB == "" {
+		return defaultDB
+	}
+	return opt.DB
+}
+
+func (opt Config) downloadDir() string {
+	if opt.DownloadDir == "" {
+		return defaultDownloadDir()
+	}
+	return opt.DownloadDir
+}
+
+func (opt Config) configReplicas() int {
+	if opt.ConfigReplicas == 0 {
+		return 1
+	}
+	return opt.ConfigReplicas
+}
+
+func (opt Config) credentials() *Credentials {
+	if opt.Auth == nil {
+		return nil
+	}
+	cr := opt.Auth.withDefaults()
+	return &cr
+}
+
+// Validate checks that configuration is complete and consistent and
+// returns *ConfigError describing every problem found. Run fails with
+// this error before starting anything.
+func (opt Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if opt.Log == nil {
+		add("Log is nil: set Log, e.g. to zap.NewNop()")
+	}
+	if opt.SetupTimeout <= 0 {
+		add("SetupTimeout is %s: set positive timeout, e.g. time.Minute", opt.SetupTimeout)
+	}
+	if opt.StartRetries < 0 {
+		add("StartRetries is %d: set zero or positive count", opt.StartRetries)
+	}
+	for name, s := range opt.ReplicaSetSettings {
+		if s.ElectionTimeout < 0 || s.HeartbeatInterval < 0 || s.CatchUpTimeout < 0 {
+			add("ReplicaSetSettings[%q] has negative duration: set positive value or zero for default", name)
+		}
+	}
+	for name, size := range opt.DiskSizes {
+		if size <= 0 {
+			add("DiskSizes[%q] is %d: set positive size in bytes or remove entry", name, size)
+		}
+	}
+	for t, s := range opt.Scheduling {
+		if s.Nice < -20 || s.Nice > 19 {
+			add("Scheduling[%s].Nice is %d: set value from -20 to 19", t, s.Nice)
+		}
+		for _, cpu := range s.CPUs {
+			if cpu < 0 {
+				add("Scheduling[%s].CPUs has %d: set zero or positive core index", t, cpu)
+			}
+		}
+	}
+	if opt.Download != "" && opt.BinaryDir != "" {
+		add("Download is set with BinaryDir: unset one of them, binaries are either downloaded or pre-provisioned")
+	}
+	if opt.EnablePartitions && opt.RunAs != nil {
+		add("EnablePartitions is set with RunAs: unset one of them, services of other user can't be placed into cgroups")
+	}
+	problems = append(problems, opt.Naming.withDefaults().validate()...)
+	for _, coll := range opt.Collections {
+		problems = append(problems, coll.validate()...)
+	}
+	sidecars := map[string]bool{}
+	for i, s := range opt.Sidecars {
+		problems = append(problems, s.validate(i)...)
+		if s.Name != "" && sidecars[s.Name] {
+			add("Sidecars[%d].Name %q is duplicate: set unique service name", i, s.Name)
+		}
+		sidecars[s.Name] = true
+	}
+	if opt.FerretDB != "" {
+		if opt.Auth != nil {
+			add("Auth is set with FerretDB: unset Auth, FerretDB has no access control")
+		}
+		// Sharding options are ignored.
+		if len(problems) > 0 {
+			return &ConfigError{Problems: problems}
+		}
+		return nil
+	}
+
+	if opt.MaxCacheGB < 0 || (opt.MaxCacheGB > 0 && opt.MaxCacheGB < minCacheGB) {
+		add("MaxCacheGB is %v: set zero for mongod default or at least %v", opt.MaxCacheGB, minCacheGB)
+	}
+	if opt.ChunkSizeMB < 0 || opt.ChunkSizeMB > maxChunkSizeMB {
+		add("ChunkSizeMB is %d: set zero for default or value from 1 to %d", opt.ChunkSizeMB, maxChunkSizeMB)
+	}
+	if opt.MirrorReadsSamplingRate < 0 || opt.MirrorReadsSamplingRate > 1 {
+		add("MirrorReadsSamplingRate is %v: set value from 0 to 1", opt.MirrorReadsSamplingRate)
+	}
+
+	if opt.ConfigReplicas < 0 || opt.ConfigReplicas > maxConfigReplicas {
+		add("ConfigReplicas is %d: set zero for single config server or value from 1 to %d", opt.ConfigReplicas, maxConfigReplicas)
+	}
+
+	specs := opt.shardSpecs()
+	if len(specs) == 0 {
+		fix := "set Shards or ShardSpecs"
+		if opt.OnSetup != nil || len(opt.Collections) > 0 {
+			fix += ", OnSetup and Collections need sharded cluster"
+		}
+		add("no shards: %s", fix)
+	}
+	for i, s := range specs {
+		if s.Replicas < 1 {
+			add("shard %d has %d replicas: set Replicas or ShardSpecs[%d].Replicas to at least 1", i, s.Replicas, i)
+		}
+		if s.members() > maxMembers {
+			add("shard %d has %d members: set at most %d", i, s.members(), maxMembers)
+		}
+	}
+	if n := maxShards(opt.Coordinate); len(specs) > n {
+		fix := "ports of members would exceed 65535"
+		if opt.Coordinate {
+			fix = fmt.Sprintf("ports of members would exceed port range of %d ports reserved by Coordinate", slotPorts)
+		}
+		add("%d shards requested: set at most %d, %s", len(specs), n, fix)
+	}
+	for _, b := range []struct{ Name, Path string }{
+		{"Mongod", opt.Mongod},
+		{"Mongos", opt.Mongos},
+	} {
+		if b.Path != "" && !binaryExists(b.Path) {
+			add("%s is %q: binary does not exist, set existing path or unset for lookup", b.Name, b.Path)
+		}
+	}
+	if opt.BinaryDir != "" && !isDir(opt.BinaryDir) {
+		add("BinaryDir is %q: directory does not exist, set directory with mongod and mongos", opt.BinaryDir)
+	}
+	for i, s := range opt.ShardSpecs {
+		if s.Mongod != "" && !binaryExists(s.Mongod) {
+			add("ShardSpecs[%d].Mongod is %q: binary does not exist, set existing path or unset for Mongod", i, s.Mongod)
+		}
+		if s.MaxCacheGB < 0 || (s.MaxCacheGB > 0 && s.MaxCacheGB < minCacheGB) {
+			add("ShardSpecs[%d].MaxCacheGB is %v: set zero for Config value or at least %v", i, s.MaxCacheGB, minCacheGB)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
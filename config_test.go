@@ -0,0 +1,87 @@
+package booga
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestConfigValidate(t *testing.T) {
+	if err := Minimal().Validate(); err != nil {
+		t.Fatalf("minimal: %v", err)
+	}
+	if err := (Config{Log: zap.NewNop(), SetupTimeout: time.Second, FerretDB: "ferretdb"}).Validate(); err != nil {
+		t.Fatalf("ferretdb: %v", err)
+	}
+	if err := (Config{Log: zap.NewNop(), SetupTimeout: time.Second, FerretDB: "ferretdb", Auth: &Credentials{}}).Validate(); err == nil {
+		t.Fatal("ferretdb with auth: expected error")
+	}
+	if err := ProductionLike().Validate(); err != nil {
+		t.Fatalf("production-like: %v", err)
+	}
+
+	// Ports of members of every shard fit into port range of slot.
+	opt := Minimal()
+	opt.Shards = 10
+	opt.Replicas = maxMembers
+	if err := opt.Validate(); err != nil {
+		t.Fatalf("10 shards: %v", err)
+	}
+	opt.Shards = 11
+	if err := opt.Validate(); err == nil || !strings.Contains(err.Error(), "at most 10") {
+		t.Fatalf("11 shards: unexpected error %v", err)
+	}
+	opt.Coordinate = false
+	if err := opt.Validate(); err != nil {
+		t.Fatalf("11 shards without coordinate: %v", err)
+	}
+	opt.Shards = 366
+	if err := opt.Validate(); err == nil || !strings.Contains(err.Error(), "65535") {
+		t.Fatalf("366 shards: unexpected error %v", err)
+	}
+
+	opt = Minimal()
+	opt.Mongod = filepath.Join("testdata", "missing", "mongod")
+	opt.Mongos = "mongos-missing-binary"
+	opt.BinaryDir = filepath.Join("testdata", "missing")
+	err := opt.Validate()
+	for _, problem := range []string{"Mongod is", "Mongos is", "BinaryDir is"} {
+		if err == nil || !strings.Contains(err.Error(), problem) {
+			t.Errorf("problem %q is not reported in %v", problem, err)
+		}
+	}
+
+	opt = Minimal()
+	opt.DB = ""
+	opt.Shards = 7
+	opt.MaxCacheGB = 0.1
+	opt.ShardSpecs = []ShardSpec{{Replicas: -1}, {Replicas: 1}, {Replicas: 1}, {Replicas: 1}, {Replicas: 1}, {Replicas: 101}}
+	opt.SetupTimeout = 0
+	opt.ChunkSizeMB = 2048
+	opt.ConfigReplicas = 8
+
+	err = opt.Validate()
+	cfgErr, ok := err.(*ConfigError)
+	if !ok {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, problem := range []string{"SetupTimeout", "MaxCacheGB", "ChunkSizeMB", "ConfigReplicas", "shard 0 has -1 replicas", "shard 5 has 101 members"} {
+		found := false
+		for _, p := range cfgErr.Problems {
+			if strings.Contains(p, problem) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("problem %q is not reported in %v", problem, cfgErr.Problems)
+		}
+	}
+	for _, p := range cfgErr.Problems {
+		if strings.Contains(p, "DB") {
+			t.Errorf("empty DB is reported: %s", p)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package booga
+
+import (
+	"context"
+	"net/url"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"golang.org/x/xerrors"
+)
+
+// Divergence is mismatch of collection hash between shard primary and
+// secondary.
+type Divergence struct {
+	Shard      string
+	Member     string // host:port of secondary
+	DB         string
+	Collection string
+	Primary    string // hash on primary, empty if collection is missing
+	Secondary  string // hash on secondary, empty if collection is missing
+}
+
+// ConsistencyReport is result of CheckConsistency.
+type ConsistencyReport struct {
+	Divergences []Divergence
+}
+
+// OK reports whether every secondary matches its primary.
+func (r *ConsistencyReport) OK() bool {
+	return len(r.Divergences) == 0
+}
+
+// dbHashes is collection hashes of every database of replica set member.
+type dbHashes map[string]map[string]string
+
+// connectMember connects directly to replica set member, allowing reads
+// from secondary.
+func (c *Cluster) connectMember(ctx context.Context, addr string) (*mongo.Client, error) {
+	u := &url.URL{Scheme: "mongodb", User: c.userinfo(), Host: addr, Path: "/", RawQuery: "directConnection=true"}
+	return connect(ctx, u.String(), options.Client().SetReadPreference(readpref.SecondaryPreferred()))
+}
+
+// memberHashes runs dbHash on every database of replica set member.
+func (c *Cluster) memberHashes(ctx context.Context, addr string, databases []string) (dbHashes, error) {
+	client, err := c.connectMember(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	hashes := dbHashes{}
+	for _, db := range databases {
+		var reply struct {
+			Collections map[string]string `bson:"collections"`
+		}
+		if err := client.Database(db).
+			RunCommand(ctx, bson.M{"dbHash": 1}).
+			Decode(&reply); err != nil {
+			return nil, xerrors.Errorf("dbHash %s: %w", db, err)
+		}
+		hashes[db] = reply.Collections
+	}
+
+	return hashes, nil
+}
+
+// diffHashes returns divergences of secondary hashes from primary ones.
+func diffHashes(primary, secondary dbHashes) []Divergence {
+	var result []Divergence
+	for db, collections := range primary {
+		names := map[string]struct{}{}
+		for name := range collections {
+			names[name] = struct{}{}
+		}
+		for name := range secondary[db] {
+			names[name] = struct{}{}
+		}
+		for name := range names {
+			p, s := collections[name], secondary[db][name]
+			if p == s {
+				continue
+			}
+			result = append(result, Divergence{
+				DB:         db,
+				Collection: name,
+				Primary:    p,
+				Secondary:  s,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].DB != result[j].DB {
+			return result[i].DB < result[j].DB
+		}
+		return result[i].Collection < result[j].Collection
+	})
+
+	return result
+}
+
+// shardDatabases returns names of databases of shard that are replicated
+// identically.
+func shardDatabases(ctx context.Context, client *mongo.Client) ([]string, error) {
+	names, err := client.ListDatabaseNames(ctx, bson.M{})
+	if err != nil {
+		return nil, xerrors.Errorf("list: %w", err)
+	}
+
+	var databases []string
+	for _, name := range names {
+		switch name {
+		case "local", "config":
+			// Contain per-member state.
+			continue
+		}
+		databases = append(databases, name)
+	}
+
+	return databases, nil
+}
+
+// CheckConsistency waits for secondaries to catch up and compares dbHash
+// of every collection on each secondary of every shard with its primary.
+//
+// Cluster should not receive writes during check.
+func (c *Cluster) CheckConsistency(ctx context.Context) (*ConsistencyReport, error) {
+	if err := c.WaitSecondariesCaughtUp(ctx); err != nil {
+		return nil, xerrors.Errorf("wait: %w", err)
+	}
+
+	report := &ConsistencyReport{}
+	for shardID := 0; shardID < c.shards; shardID++ {
+		rsName := c.shardReplicaSet(shardID)
+		if err := withClient(ctx, c.shardURI(shardID), func(client *mongo.Client) error {
+			status, err := replSetGetStatus(ctx, client)
+			if err != nil {
+				return err
+			}
+			databases, err := shardDatabases(ctx, client)
+			if err != nil {
+				return xerrors.Errorf("databases: %w", err)
+			}
+
+			var (
+				primary     string
+				secondaries []string
+			)
+			for _, m := range status.Members {
+				switch m.State {
+				case statePrimary:
+					primary = m.Name
+				case stateSecondary:
+					secondaries = append(secondaries, m.Name)
+				}
+			}
+			if primary == "" {
+				return xerrors.New("no primary")
+			}
+
+			primaryHashes, err := c.memberHashes(ctx, primary, databases)
+			if err != nil {
+				return xerrors.Errorf("primary %s: %w", primary, err)
+			}
+			for _, addr := range secondaries {
+				hashes, err := c.memberHashes(ctx, addr, databases)
+				if err != nil {
+					return xerrors.Errorf("secondary %s: %w", addr, err)
+				}
+				for _, d := range diffHashes(primaryHashes, hashes) {
+					d.Shard = rsName
+					d.Member = addr
+					report.Divergences = append(report.Divergences, d)
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return nil, xerrors.Errorf("check %s: %w", rsName, err)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,26 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffHashes(t *testing.T) {
+	primary := dbHashes{
+		"cloud": {"users": "a", "orders": "b", "events": "c"},
+	}
+	secondary := dbHashes{
+		"cloud": {"users": "a", "orders": "x", "extra": "d"},
+	}
+	expected := []Divergence{
+		{DB: "cloud", Collection: "events", Primary: "c"},
+		{DB: "cloud", Collection: "extra", Secondary: "d"},
+		{DB: "cloud", Collection: "orders", Primary: "b", Secondary: "x"},
+	}
+	if got := diffHashes(primary, secondary); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v", got)
+	}
+	if got := diffHashes(primary, primary); len(got) != 0 {
+		t.Errorf("same hashes: %+v", got)
+	}
+}
@@ -0,0 +1,91 @@
+package booga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// coreFiles returns core files in dir, named "core" or "core.<suffix>"
+// by default kernel core pattern.
+func coreFiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if name := e.Name(); name == "core" || strings.HasPrefix(name, "core.") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return files, nil
+}
+
+// moveFile moves file, copying it if rename is not possible, e.g. between
+// file systems.
+func moveFile(dst, src string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(dst, src); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// collectCores moves core files of crashed service from its working
+// directory to core dump directory with description of binary, so they
+// are not removed with data directory.
+func (c *Cluster) collectCores(ctx context.Context, opt serverOptions, dir string) error {
+	files, err := coreFiles(dir)
+	if err != nil {
+		return xerrors.Errorf("list: %w", err)
+	}
+	if len(files) == 0 {
+		c.log.Warn("No core files found, check kernel core pattern",
+			zap.String("name", opt.Name),
+			zap.String("dir", dir),
+		)
+		return nil
+	}
+
+	dst := filepath.Join(c.coreDumpDir, fmt.Sprintf("%s-%d", opt.Name, time.Now().UnixNano()))
+	if err := ensureDir(dst); err != nil {
+		return xerrors.Errorf("ensure: %w", err)
+	}
+	for _, f := range files {
+		if err := moveFile(filepath.Join(dst, filepath.Base(f)), f); err != nil {
+			return xerrors.Errorf("move %s: %w", f, err)
+		}
+	}
+	b, err := inspectBinary(ctx, opt.BinaryPath)
+	if err != nil {
+		return xerrors.Errorf("inspect: %w", err)
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("marshal: %w", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dst, "binary.json"), data, 0600); err != nil {
+		return xerrors.Errorf("write: %w", err)
+	}
+	c.log.Warn("Core files collected",
+		zap.String("name", opt.Name),
+		zap.String("dir", dst),
+		zap.Int("files", len(files)),
+	)
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package booga
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"golang.org/x/xerrors"
+)
+
+// corePatternPath is kernel setting of core file names, see core(5).
+const corePatternPath = "/proc/sys/kernel/core_pattern"
+
+// checkCorePattern returns error if kernel core pattern read from path
+// pipes core files to program, e.g. systemd-coredump or apport, so they
+// are not written to working directory of service.
+func checkCorePattern(path string) error {
+	pattern, err := ioutil.ReadFile(path)
+	if err != nil {
+		return xerrors.Errorf("read: %w", err)
+	}
+	pattern = bytes.TrimSpace(pattern)
+	if bytes.HasPrefix(pattern, []byte("|")) {
+		return xerrors.Errorf("core files are piped to %q: set kernel.core_pattern to file name, e.g. \"core\"", pattern[1:])
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package booga
+
+const corePatternPath = ""
+
+// checkCorePattern is no-op, core pattern is checked only on linux.
+func checkCorePattern(path string) error {
+	return nil
+}
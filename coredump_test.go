@@ -0,0 +1,61 @@
+package booga
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCoreFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "booga-core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	for _, name := range []string{"core", "core.1234", "mongod.lock", "WiredTiger.wt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "core.dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := coreFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "core"), filepath.Join(dir, "core.1234")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCheckCorePattern(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Core pattern is checked only on linux")
+	}
+	dir, err := ioutil.TempDir("", "booga-core-pattern")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "core_pattern")
+	for pattern, ok := range map[string]bool{
+		"core\n":                             true,
+		"core.%e.%p\n":                       true,
+		"|/usr/lib/systemd/systemd-coredump": false,
+	} {
+		if err := ioutil.WriteFile(path, []byte(pattern), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := checkCorePattern(path); (err == nil) != ok {
+			t.Errorf("%q: unexpected result %v", pattern, err)
+		}
+	}
+}
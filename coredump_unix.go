@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package booga
+
+import (
+	"os"
+	"syscall"
+)
+
+// crashed reports whether process was terminated by signal other than
+// ones used to stop services.
+func crashed(state *os.ProcessState) bool {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return false
+	}
+	switch ws.Signal() {
+	case syscall.SIGKILL, syscall.SIGTERM:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,5 @@
+package booga
+
+import "os"
+
+func crashed(state *os.ProcessState) bool { return false }
@@ -0,0 +1,100 @@
+package booga
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// logDBStats logs dbStats of database or collStats of collection ns.
+func (c *Cluster) logDBStats(ctx context.Context, client *mongo.Client, ns string) error {
+	if !strings.Contains(ns, ".") {
+		var s struct {
+			Collections int64 `bson:"collections"`
+			Objects     int64 `bson:"objects"`
+			DataSize    int64 `bson:"dataSize"`
+			StorageSize int64 `bson:"storageSize"`
+			IndexSize   int64 `bson:"indexSize"`
+		}
+		if err := client.Database(ns).
+			RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).
+			Decode(&s); err != nil {
+			return xerrors.Errorf("dbStats: %w", err)
+		}
+		c.log.Info("Database stats",
+			zap.String("db", ns),
+			zap.Int64("collections", s.Collections),
+			zap.Int64("objects", s.Objects),
+			zap.Int64("data_size", s.DataSize),
+			zap.Int64("storage_size", s.StorageSize),
+			zap.Int64("index_size", s.IndexSize),
+		)
+		return nil
+	}
+
+	dbName, collName, err := splitNamespace(ns)
+	if err != nil {
+		return err
+	}
+	var s struct {
+		Count          int64 `bson:"count"`
+		Size           int64 `bson:"size"`
+		StorageSize    int64 `bson:"storageSize"`
+		TotalIndexSize int64 `bson:"totalIndexSize"`
+		Sharded        bool  `bson:"sharded"`
+		Chunks         int64 `bson:"nchunks"`
+	}
+	if err := client.Database(dbName).
+		RunCommand(ctx, bson.D{{Key: "collStats", Value: collName}}).
+		Decode(&s); err != nil {
+		return xerrors.Errorf("collStats: %w", err)
+	}
+	c.log.Info("Collection stats",
+		zap.String("ns", ns),
+		zap.Int64("count", s.Count),
+		zap.Int64("size", s.Size),
+		zap.Int64("storage_size", s.StorageSize),
+		zap.Int64("index_size", s.TotalIndexSize),
+		zap.Bool("sharded", s.Sharded),
+		zap.Int64("chunks", s.Chunks),
+	)
+	return nil
+}
+
+// logStats periodically logs stats of Config.StatsNamespaces, or of
+// cluster database by default, until context cancellation.
+func (c *Cluster) logStats(ctx context.Context) {
+	namespaces := c.statsNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{c.db}
+	}
+
+	ticker := time.NewTicker(c.statsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !c.isReady() {
+				continue
+			}
+			if err := withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+				for _, ns := range namespaces {
+					if err := c.logDBStats(ctx, client, ns); err != nil {
+						c.log.Debug("Failed to get stats", zap.String("ns", ns), zap.Error(err))
+					}
+				}
+				return nil
+			}); err != nil {
+				c.log.Debug("Failed to connect for stats", zap.Error(err))
+			}
+		}
+	}
+}
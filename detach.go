@@ -0,0 +1,140 @@
+package booga
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// StateFile is name of detached cluster state file in cluster directory.
+const StateFile = "cluster.json"
+
+// State is description of detached cluster, persisted to StateFile.
+type State struct {
+	Dir        string        `json:"dir"`
+	DB         string        `json:"db"`
+	Shards     int           `json:"shards"`
+	Replicas   int           `json:"replicas"`
+	ShardSpecs []ShardSpec   `json:"shard_specs"`
+	PortOffset int           `json:"port_offset"`
+	Naming     Naming        `json:"naming"`
+	Services   []ServiceInfo `json:"services"`
+
+	ConfigReplicas int          `json:"config_replicas"`
+	Auth           *Credentials `json:"auth,omitempty"`
+}
+
+func (c *Cluster) statePath() string {
+	return filepath.Join(c.dir, StateFile)
+}
+
+// RunDetached starts cluster that outlives current process and returns
+// when cluster is ready. Services log to files in cluster directory.
+//
+// Cluster state is written to StateFile in cluster directory, and can be
+// used by Reattach to control and teardown cluster later.
+func (c *Cluster) RunDetached(ctx context.Context) error {
+	c.detached = true
+	if err := c.Start(ctx); err != nil {
+		return err
+	}
+
+	state := State{
+		Dir:        c.dir,
+		DB:         c.db,
+		Shards:     c.shards,
+		Replicas:   c.replicas,
+		ShardSpecs: c.shardSpecs,
+		PortOffset: c.portOffset,
+		Naming:     c.naming,
+		Services:   c.Services(),
+
+		ConfigReplicas: c.configReplicas,
+		Auth:           c.auth,
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("marshal: %w", err)
+	}
+	if err := ioutil.WriteFile(c.statePath(), data, 0600); err != nil {
+		return xerrors.Errorf("write state: %w", err)
+	}
+	c.log.Info("Cluster detached", zap.String("state", c.statePath()))
+
+	return nil
+}
+
+// processAlive reports whether process exists.
+func processAlive(p *os.Process) bool {
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// watchProcess marks service as exited when process that is not child
+// of current process exits.
+func (c *Cluster) watchProcess(name string, p *os.Process) {
+	ticker := time.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !processAlive(p) {
+			// Exit code of process that is not child is unknown.
+			c.setExited(name, -1, nil)
+			return
+		}
+	}
+}
+
+// Reattach returns handle of detached cluster from state file written
+// by RunDetached. Services of cluster can be listed, killed and torn down
+// by Close, which also removes state file.
+func Reattach(path string) (*Cluster, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("read: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, xerrors.Errorf("unmarshal: %w", err)
+	}
+
+	c := New(Config{
+		Log:        zap.NewNop(),
+		Dir:        state.Dir,
+		Shards:     state.Shards,
+		Replicas:   state.Replicas,
+		ShardSpecs: state.ShardSpecs,
+		Naming:     state.Naming,
+
+		ConfigReplicas: state.ConfigReplicas,
+		Auth:           state.Auth,
+	})
+	c.db = state.DB
+	c.portOffset = state.PortOffset
+	c.detached = true
+
+	for _, info := range state.Services {
+		s := &service{info: info}
+		s.info.State = ServiceStopped
+		if p, err := os.FindProcess(info.PID); err == nil && info.PID != 0 && processAlive(p) {
+			s.process = p
+			s.info.State = ServiceReady
+			s.cancel = func() { _ = signalGroup(p, syscall.SIGKILL) }
+		}
+		if err := c.services.add(s); err != nil {
+			return nil, err
+		}
+		if s.process != nil {
+			go c.watchProcess(info.Name, s.process)
+		}
+	}
+	c.markReady()
+
+	return c, nil
+}
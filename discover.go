@@ -0,0 +1,127 @@
+package booga
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// MongoHomeEnv is environment variable with MongoDB installation
+// directory, binaries are looked up in its bin subdirectory first.
+const MongoHomeEnv = "MONGODB_HOME"
+
+// wellKnownDirs are common install locations of MongoDB binaries that
+// can be missing from PATH.
+var wellKnownDirs = []string{
+	"/opt/homebrew/bin",
+	"/usr/local/bin",
+	"/usr/bin",
+	"/opt/mongodb/bin",
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// binaryExists reports whether binary path is file or, if path is bare
+// name, binary is found in PATH.
+func binaryExists(path string) bool {
+	if filepath.Base(path) == path {
+		_, err := exec.LookPath(path)
+		return err == nil
+	}
+	return isFile(path)
+}
+
+// findBinary looks up binary only in dir if set, otherwise in
+// MongoHomeEnv, PATH and well-known directories, in that order.
+func findBinary(dir, name string) (string, error) {
+	file := name
+	if runtime.GOOS == "windows" {
+		file += ".exe"
+	}
+	if dir != "" {
+		path := filepath.Join(dir, file)
+		if !isFile(path) {
+			return "", xerrors.Errorf("%s not found in binary directory %s", name, dir)
+		}
+		return path, nil
+	}
+	var dirs []string
+	if home := os.Getenv(MongoHomeEnv); home != "" {
+		dir := filepath.Join(home, "bin")
+		if path := filepath.Join(dir, file); isFile(path) {
+			return path, nil
+		}
+		dirs = append(dirs, dir)
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+	dirs = append(dirs, "PATH")
+	for _, dir := range wellKnownDirs {
+		if path := filepath.Join(dir, file); isFile(path) {
+			return path, nil
+		}
+		dirs = append(dirs, dir)
+	}
+
+	return "", xerrors.Errorf("%s not found in %s", name, strings.Join(dirs, ", "))
+}
+
+// discoverBinaries finds mongod and mongos binaries that are not set,
+// downloading them if Config.Download is set.
+func (c *Cluster) discoverBinaries(ctx context.Context) error {
+	dir := c.binaryDir
+	if c.download != "" && (c.mongod == "" || c.mongos == "") {
+		downloaded, err := c.downloadBinaries(ctx)
+		if err != nil {
+			return xerrors.Errorf("download: %w", err)
+		}
+		dir = downloaded
+	}
+	for _, b := range []struct {
+		name string
+		path *string
+	}{
+		{name: "mongod", path: &c.mongod},
+		{name: "mongos", path: &c.mongos},
+	} {
+		if *b.path != "" {
+			continue
+		}
+		path, err := findBinary(dir, b.name)
+		if err != nil {
+			return err
+		}
+		*b.path = path
+
+		info, err := inspectBinary(ctx, path)
+		if err != nil {
+			return xerrors.Errorf("inspect %s: %w", path, err)
+		}
+		c.log.Info("Binary found",
+			zap.String("path", info.Path),
+			zap.String("version", info.Version),
+		)
+	}
+	for i := range c.shardSpecs {
+		if c.shardSpecs[i].Mongod == "" {
+			c.shardSpecs[i].Mongod = c.mongod
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package booga
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFindBinary(t *testing.T) {
+	home, err := ioutil.TempDir("", "booga-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(home) }()
+
+	const name = "booga-test-mongod"
+	file := name
+	if runtime.GOOS == "windows" {
+		file += ".exe"
+	}
+	if err := os.MkdirAll(filepath.Join(home, "bin"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	expected := filepath.Join(home, "bin", file)
+	if err := ioutil.WriteFile(expected, nil, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	prev := os.Getenv(MongoHomeEnv)
+	if err := os.Setenv(MongoHomeEnv, home); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Setenv(MongoHomeEnv, prev) }()
+
+	got, err := findBinary("", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expected {
+		t.Errorf("got %s, want %s", got, expected)
+	}
+	if _, err := findBinary("", "booga-test-missing"); err == nil {
+		t.Error("expected error for missing binary")
+	}
+
+	// Binary directory is exclusive.
+	dir := filepath.Join(home, "cache")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, file), nil, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := findBinary(dir, name); err != nil || got != filepath.Join(dir, file) {
+		t.Errorf("got %s (%v), want binary from %s", got, err, dir)
+	}
+	if err := os.Remove(filepath.Join(dir, file)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := findBinary(dir, name); err == nil || !strings.Contains(err.Error(), dir) {
+		t.Errorf("got %v, want error naming %s", err, dir)
+	}
+}
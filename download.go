@@ -0,0 +1,247 @@
+package booga
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// downloadURL is base URL of MongoDB Community Server archives.
+const downloadURL = "https://fastdl.mongodb.org"
+
+// defaultDownloadDir returns default cache directory of downloaded
+// binaries.
+func defaultDownloadDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "booga")
+}
+
+// downloadArchive returns path of MongoDB archive of version for target
+// relative to downloadURL, e.g. "linux/mongodb-linux-x86_64-ubuntu2204-7.0.14.tgz".
+func downloadArchive(goos, goarch, target, version string) (string, error) {
+	switch goos {
+	case "linux":
+		arch := map[string]string{"amd64": "x86_64", "arm64": "aarch64"}[goarch]
+		if arch == "" {
+			return "", xerrors.Errorf("no MongoDB builds for linux/%s", goarch)
+		}
+		if target == "" {
+			return "", xerrors.New("unknown linux distribution: set DownloadTarget, e.g. \"ubuntu2204\"")
+		}
+		return fmt.Sprintf("linux/mongodb-linux-%s-%s-%s.tgz", arch, target, version), nil
+	case "darwin":
+		arch := map[string]string{"amd64": "x86_64", "arm64": "arm64"}[goarch]
+		if arch == "" {
+			return "", xerrors.Errorf("no MongoDB builds for darwin/%s", goarch)
+		}
+		return fmt.Sprintf("osx/mongodb-macos-%s-%s.tgz", arch, version), nil
+	default:
+		return "", xerrors.Errorf("downloads are not supported on %s", goos)
+	}
+}
+
+// parseOSRelease returns MongoDB build target of linux distribution from
+// /etc/os-release, e.g. "ubuntu2204" or "rhel8", or empty string if
+// distribution is unknown.
+func parseOSRelease(r io.Reader) string {
+	fields := map[string]string{}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		kv := strings.SplitN(s.Text(), "=", 2)
+		if len(kv) == 2 {
+			fields[kv[0]] = strings.Trim(kv[1], `"'`)
+		}
+	}
+	version := fields["VERSION_ID"]
+	major := strings.SplitN(version, ".", 2)[0]
+	switch fields["ID"] {
+	case "ubuntu":
+		return "ubuntu" + strings.Replace(version, ".", "", 1)
+	case "debian":
+		return "debian" + major + "0"
+	case "rhel", "centos", "rocky", "almalinux", "ol":
+		return "rhel" + major + "0"
+	case "amzn":
+		if version == "2" {
+			return "amazon2"
+		}
+		return "amazon" + version
+	default:
+		return ""
+	}
+}
+
+// downloadTarget returns build target of current linux distribution.
+func downloadTarget() string {
+	f, err := os.Open("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	return parseOSRelease(f)
+}
+
+// binaryDownloader downloads MongoDB binaries to cache directory.
+type binaryDownloader struct {
+	client  *http.Client
+	baseURL string
+	dir     string // cache directory
+	offline bool
+	log     *zap.Logger
+}
+
+// binDir returns directory of mongod and mongos from archive in cache,
+// downloading archive if needed.
+func (d binaryDownloader) binDir(ctx context.Context, archive string) (string, error) {
+	name := strings.TrimSuffix(path.Base(archive), ".tgz")
+	dir := filepath.Join(d.dir, name)
+	if isFile(filepath.Join(dir, "mongod")) && isFile(filepath.Join(dir, "mongos")) {
+		return dir, nil
+	}
+	if d.offline {
+		return "", xerrors.Errorf("%s is not in download cache %s and downloads are disabled by Offline: put binaries to %s or disable Offline",
+			name, d.dir, dir,
+		)
+	}
+
+	if err := ensureDir(d.dir); err != nil {
+		return "", xerrors.Errorf("ensure: %w", err)
+	}
+	tmp, err := ioutil.TempDir(d.dir, name+".tmp")
+	if err != nil {
+		return "", xerrors.Errorf("temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	url := d.baseURL + "/" + archive
+	d.log.Info("Downloading binaries", zap.String("url", url))
+	sum, err := d.get(ctx, url+".sha256")
+	if err != nil {
+		return "", xerrors.Errorf("checksum: %w", err)
+	}
+	fields := strings.Fields(string(sum))
+	if len(fields) == 0 {
+		return "", xerrors.New("empty checksum")
+	}
+	data, err := d.get(ctx, url)
+	if err != nil {
+		return "", xerrors.Errorf("archive: %w", err)
+	}
+	if got := sha256.Sum256(data); hex.EncodeToString(got[:]) != strings.ToLower(fields[0]) {
+		return "", xerrors.Errorf("checksum mismatch of %s", url)
+	}
+	if err := extractBinaries(data, tmp, "mongod", "mongos"); err != nil {
+		return "", xerrors.Errorf("extract: %w", err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		if isFile(filepath.Join(dir, "mongod")) {
+			// Concurrent download completed first.
+			return dir, nil
+		}
+		return "", xerrors.Errorf("rename: %w", err)
+	}
+
+	return dir, nil
+}
+
+func (d binaryDownloader) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := d.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("GET %s: %s", url, res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// extractBinaries writes bin/<name> files of tgz archive to dir.
+func extractBinaries(data []byte, dir string, names ...string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	want := map[string]bool{}
+	for _, name := range names {
+		want[name] = true
+	}
+
+	r := tar.NewReader(gz)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Base(h.Name)
+		if h.Typeflag != tar.TypeReg || path.Base(path.Dir(h.Name)) != "bin" || !want[name] {
+			continue
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		delete(want, name)
+	}
+	for _, name := range names {
+		if want[name] {
+			return xerrors.Errorf("no %s in archive", name)
+		}
+	}
+	return nil
+}
+
+// downloadBinaries returns directory with downloaded binaries of
+// Config.Download version.
+func (c *Cluster) downloadBinaries(ctx context.Context) (string, error) {
+	target := c.downloadTarget
+	if target == "" && runtime.GOOS == "linux" {
+		target = downloadTarget()
+	}
+	archive, err := downloadArchive(runtime.GOOS, runtime.GOARCH, target, c.download)
+	if err != nil {
+		return "", err
+	}
+	d := binaryDownloader{
+		client:  http.DefaultClient,
+		baseURL: downloadURL,
+		dir:     c.downloadDir,
+		offline: c.offline,
+		log:     c.log,
+	}
+	return d.binDir(ctx, archive)
+}
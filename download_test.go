@@ -0,0 +1,132 @@
+package booga
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDownloadArchive(t *testing.T) {
+	for _, tt := range []struct {
+		OS, Arch, Target string
+		Expected         string
+	}{
+		{"linux", "amd64", "ubuntu2204", "linux/mongodb-linux-x86_64-ubuntu2204-7.0.14.tgz"},
+		{"linux", "arm64", "rhel80", "linux/mongodb-linux-aarch64-rhel80-7.0.14.tgz"},
+		{"darwin", "arm64", "", "osx/mongodb-macos-arm64-7.0.14.tgz"},
+	} {
+		got, err := downloadArchive(tt.OS, tt.Arch, tt.Target, "7.0.14")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.Expected {
+			t.Errorf("got %s, want %s", got, tt.Expected)
+		}
+	}
+	if _, err := downloadArchive("linux", "amd64", "", "7.0.14"); err == nil {
+		t.Error("expected error for unknown distribution")
+	}
+	if _, err := downloadArchive("windows", "amd64", "", "7.0.14"); err == nil {
+		t.Error("expected error for windows")
+	}
+}
+
+func TestParseOSRelease(t *testing.T) {
+	for input, expected := range map[string]string{
+		"ID=ubuntu\nVERSION_ID=\"22.04\"\n":  "ubuntu2204",
+		"ID=debian\nVERSION_ID=\"12\"\n":     "debian120",
+		"ID=\"rocky\"\nVERSION_ID=\"8.9\"\n": "rhel80",
+		"ID=\"amzn\"\nVERSION_ID=\"2023\"\n": "amazon2023",
+		"ID=arch\n":                          "",
+	} {
+		if got := parseOSRelease(strings.NewReader(input)); got != expected {
+			t.Errorf("%q: got %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestBinaryDownloader(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"mongodb-linux/bin/mongod", "mongodb-linux/bin/mongos", "mongodb-linux/LICENSE"} {
+		body := []byte("#!/bin/sh\n")
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(archive.Bytes())
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/linux/mongodb-linux.tgz":
+			_, _ = w.Write(archive.Bytes())
+		case "/linux/mongodb-linux.tgz.sha256":
+			_, _ = w.Write([]byte(hex.EncodeToString(sum[:]) + "  mongodb-linux.tgz\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "booga-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	d := binaryDownloader{client: srv.Client(), baseURL: srv.URL, dir: dir, offline: true, log: zap.NewNop()}
+	ctx := context.Background()
+	if _, err := d.binDir(ctx, "linux/mongodb-linux.tgz"); err == nil || !strings.Contains(err.Error(), dir) {
+		t.Fatalf("offline: unexpected error %v", err)
+	}
+	if requests != 0 {
+		t.Fatal("offline downloader made requests")
+	}
+
+	d.offline = false
+	got, err := d.binDir(ctx, "linux/mongodb-linux.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "mongodb-linux"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	for _, name := range []string{"mongod", "mongos"} {
+		if !isFile(filepath.Join(got, name)) {
+			t.Errorf("no %s", name)
+		}
+	}
+
+	// Cached binaries are used offline.
+	d.offline = true
+	if _, err := d.binDir(ctx, "linux/mongodb-linux.tgz"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.binDir(ctx, "linux/missing.tgz"); err == nil {
+		t.Error("expected error for missing archive")
+	}
+}
@@ -0,0 +1,201 @@
+package booga
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/xerrors"
+)
+
+// ChangeEvent is change stream event.
+type ChangeEvent struct {
+	Namespace string // "db.collection"
+	Operation string // e.g. "insert", "update" or "delete"
+	Raw       bson.Raw
+}
+
+// EventFilter selects change events. Zero value selects every event.
+type EventFilter struct {
+	Namespace  string   // "db.collection" or "db" for whole database
+	Operations []string // operation types
+}
+
+func (f EventFilter) match(e ChangeEvent) bool {
+	if f.Namespace != "" && f.Namespace != e.Namespace {
+		db, _, err := splitNamespace(e.Namespace)
+		if err != nil || db != f.Namespace {
+			return false
+		}
+	}
+	if len(f.Operations) == 0 {
+		return true
+	}
+	for _, op := range f.Operations {
+		if op == e.Operation {
+			return true
+		}
+	}
+	return false
+}
+
+type eventSubscriber struct {
+	filter EventFilter
+	events chan ChangeEvent
+	done   chan struct{}
+}
+
+// EventBridge fans out events of cluster-wide change stream to
+// subscribed channels.
+type EventBridge struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+
+	mux  sync.Mutex
+	subs map[*eventSubscriber]struct{}
+}
+
+// Subscribe returns channel of events matching filter and function that
+// cancels subscription.
+//
+// Events are delivered in order and are not dropped, so slow subscriber
+// blocks others. Channel is closed when bridge is closed.
+func (b *EventBridge) Subscribe(filter EventFilter) (<-chan ChangeEvent, func()) {
+	s := &eventSubscriber{
+		filter: filter,
+		events: make(chan ChangeEvent, 64),
+		done:   make(chan struct{}),
+	}
+
+	b.mux.Lock()
+	if b.subs == nil {
+		// Bridge is closed.
+		close(s.events)
+	} else {
+		b.subs[s] = struct{}{}
+	}
+	b.mux.Unlock()
+
+	var once sync.Once
+	return s.events, func() {
+		once.Do(func() {
+			b.mux.Lock()
+			delete(b.subs, s)
+			b.mux.Unlock()
+			close(s.done)
+		})
+	}
+}
+
+func (b *EventBridge) subscribers() []*eventSubscriber {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	subs := make([]*eventSubscriber, 0, len(b.subs))
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+func (b *EventBridge) dispatch(e ChangeEvent) {
+	for _, s := range b.subscribers() {
+		if !s.filter.match(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		case <-s.done:
+		case <-b.ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *EventBridge) run(stream *mongo.ChangeStream) error {
+	for stream.Next(b.ctx) {
+		var event struct {
+			Operation string `bson:"operationType"`
+			NS        struct {
+				DB   string `bson:"db"`
+				Coll string `bson:"coll"`
+			} `bson:"ns"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			return xerrors.Errorf("decode: %w", err)
+		}
+		ns := event.NS.DB
+		if event.NS.Coll != "" {
+			ns += "." + event.NS.Coll
+		}
+
+		b.dispatch(ChangeEvent{
+			Namespace: ns,
+			Operation: event.Operation,
+			// Current document is reused by stream.
+			Raw: append(bson.Raw(nil), stream.Current...),
+		})
+	}
+	if err := stream.Err(); err != nil && b.ctx.Err() == nil {
+		return xerrors.Errorf("next: %w", err)
+	}
+
+	return nil
+}
+
+// Close stops change stream, closes subscribed channels and returns
+// error of change stream, if any.
+func (b *EventBridge) Close() error {
+	b.cancel()
+	<-b.done
+	return b.err
+}
+
+// Events opens cluster-wide change stream through routing server and
+// returns bridge that fans out events to subscribers until Close or
+// context cancellation.
+//
+// Only events that happen after call are delivered.
+func (c *Cluster) Events(ctx context.Context) (*EventBridge, error) {
+	client, err := connect(ctx, c.routerURI())
+	if err != nil {
+		return nil, err
+	}
+	stream, err := client.Watch(ctx, mongo.Pipeline{}, options.ChangeStream().
+		SetFullDocument(options.UpdateLookup),
+	)
+	if err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, xerrors.Errorf("watch: %w", err)
+	}
+
+	bCtx, cancel := context.WithCancel(ctx)
+	b := &EventBridge{
+		ctx:    bCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		subs:   map[*eventSubscriber]struct{}{},
+	}
+	go func() {
+		defer close(b.done)
+		defer func() {
+			_ = stream.Close(context.Background())
+			_ = client.Disconnect(context.Background())
+		}()
+
+		b.err = b.run(stream)
+
+		b.mux.Lock()
+		for s := range b.subs {
+			close(s.events)
+		}
+		b.subs = nil
+		b.mux.Unlock()
+	}()
+
+	return b, nil
+}
@@ -0,0 +1,23 @@
+package booga
+
+import "testing"
+
+func TestEventFilterMatch(t *testing.T) {
+	insert := ChangeEvent{Namespace: "cloud.users", Operation: "insert"}
+	for _, tt := range []struct {
+		Filter EventFilter
+		Match  bool
+	}{
+		{Filter: EventFilter{}, Match: true},
+		{Filter: EventFilter{Namespace: "cloud.users"}, Match: true},
+		{Filter: EventFilter{Namespace: "cloud"}, Match: true},
+		{Filter: EventFilter{Namespace: "cloud.orders"}, Match: false},
+		{Filter: EventFilter{Namespace: "other"}, Match: false},
+		{Filter: EventFilter{Operations: []string{"update", "insert"}}, Match: true},
+		{Filter: EventFilter{Namespace: "cloud", Operations: []string{"delete"}}, Match: false},
+	} {
+		if got := tt.Filter.match(insert); got != tt.Match {
+			t.Errorf("%+v: got %v", tt.Filter, got)
+		}
+	}
+}
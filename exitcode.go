@@ -0,0 +1,58 @@
+package booga
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// Errors of well-known mongod exit codes, can be checked by xerrors.Is or
+// errors.Is on error returned by Run.
+var (
+	ErrBadOptions            = xerrors.New("invalid options")
+	ErrUncleanShutdown       = xerrors.New("unrecoverable error or unclean shutdown")
+	ErrAddressInUse          = xerrors.New("address already in use")
+	ErrDataFilesIncompatible = xerrors.New("data files are incompatible with server version")
+	ErrUncaughtException     = xerrors.New("uncaught exception")
+)
+
+// exitCodeErrors maps mongod exit codes to errors.
+//
+// See https://www.mongodb.com/docs/manual/reference/exit-codes/
+var exitCodeErrors = map[int]error{
+	2:   ErrBadOptions,
+	14:  ErrUncleanShutdown,
+	48:  ErrAddressInUse,
+	62:  ErrDataFilesIncompatible,
+	100: ErrUncaughtException,
+}
+
+// ExitError is exit of service process with well-known exit code.
+type ExitError struct {
+	Service string
+	Code    int
+	Err     error // error of process wait
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s exited with code %d: %s", e.Service, e.Code, exitCodeErrors[e.Code])
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// Is reports whether target is error of exit code.
+func (e *ExitError) Is(target error) bool {
+	return target == exitCodeErrors[e.Code]
+}
+
+// exitError wraps error of process that exited with code to ExitError if
+// code is well-known.
+func exitError(name string, code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := exitCodeErrors[code]; !ok {
+		return err
+	}
+	return &ExitError{Service: name, Code: code, Err: err}
+}
@@ -0,0 +1,32 @@
+package booga
+
+import (
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+func TestExitError(t *testing.T) {
+	waitErr := xerrors.New("exit status 48")
+	err := xerrors.Errorf("run: %w", exitError("data-0-0", 48, waitErr))
+	if !xerrors.Is(err, ErrAddressInUse) {
+		t.Errorf("%v is not address in use", err)
+	}
+	if xerrors.Is(err, ErrUncleanShutdown) {
+		t.Errorf("%v is unclean shutdown", err)
+	}
+	if !xerrors.Is(err, waitErr) {
+		t.Error("wait error is not wrapped")
+	}
+	var exitErr *ExitError
+	if !xerrors.As(err, &exitErr) || exitErr.Service != "data-0-0" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if got := exitError("data-0-0", 1, waitErr); got != waitErr {
+		t.Errorf("unknown code is wrapped: %v", got)
+	}
+	if exitError("data-0-0", 48, nil) != nil {
+		t.Error("nil error is wrapped")
+	}
+}
@@ -0,0 +1,164 @@
+package booga
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+)
+
+// FailoverTiming is result of MeasureFailover.
+type FailoverTiming struct {
+	Shard      string
+	OldPrimary string // host:port
+	NewPrimary string // host:port
+	Started    time.Time
+
+	// ToNewPrimary is duration from injection until primary is elected
+	// in new term.
+	ToNewPrimary time.Duration
+	// ToFirstWrite is duration from injection until first successful
+	// write to shard through routing server.
+	ToFirstWrite time.Duration
+}
+
+// failoverProbeDB returns name of database with primary on shard that is
+// used to probe writes.
+func (c *Cluster) failoverProbeDB(shardID int) string {
+	return "booga_failover_" + c.shardReplicaSet(shardID)
+}
+
+// ensureFailoverProbe places probe database on shard.
+func (c *Cluster) ensureFailoverProbe(ctx context.Context, router *mongo.Client, shardID int) error {
+	db := c.failoverProbeDB(shardID)
+	if _, err := router.Database(db).Collection("probe").InsertOne(ctx, bson.M{}); err != nil {
+		return xerrors.Errorf("insert: %w", err)
+	}
+
+	var meta struct {
+		Primary string `bson:"primary"`
+	}
+	if err := router.Database("config").Collection("databases").
+		FindOne(ctx, bson.M{"_id": db}).
+		Decode(&meta); err != nil {
+		return xerrors.Errorf("find: %w", err)
+	}
+	if meta.Primary == c.shardReplicaSet(shardID) {
+		return nil
+	}
+	if err := router.Database("admin").
+		RunCommand(ctx, bson.M{"movePrimary": db, "to": c.shardReplicaSet(shardID)}).
+		Err(); err != nil {
+		return xerrors.Errorf("movePrimary: %w", err)
+	}
+
+	return nil
+}
+
+// MeasureFailover injects fault by n and measures time until shard has
+// new primary and time until first successful write to shard through
+// routing server. Injected fault is not recovered.
+//
+// Typical nemeses are PrimaryKiller and StepDown. Timings are logged and
+// recorded as "Failover" span.
+func (c *Cluster) MeasureFailover(ctx context.Context, shardID int, n Nemesis) (*FailoverTiming, error) {
+	if shardID < 0 || shardID >= c.shards {
+		return nil, xerrors.Errorf("no shard %d", shardID)
+	}
+	shard, err := connect(ctx, c.shardURI(shardID),
+		options.Client().SetReadPreference(readpref.PrimaryPreferred()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = shard.Disconnect(ctx) }()
+	router, err := connect(ctx, c.routerURI(), options.Client().SetRetryWrites(false))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = router.Disconnect(ctx) }()
+
+	status, err := replSetGetStatus(ctx, shard)
+	if err != nil {
+		return nil, err
+	}
+	oldPrimary, ok := status.primary()
+	if !ok {
+		return nil, xerrors.New("no primary")
+	}
+	if err := c.ensureFailoverProbe(ctx, router, shardID); err != nil {
+		return nil, xerrors.Errorf("probe: %w", err)
+	}
+
+	timing := &FailoverTiming{
+		Shard:      c.shardReplicaSet(shardID),
+		OldPrimary: oldPrimary,
+	}
+	ctx, span := c.startSpan(ctx, "Failover",
+		attribute.String("booga.shard", timing.Shard),
+		attribute.String("booga.old_primary", oldPrimary),
+	)
+	timing.Started = time.Now()
+	if err := n.Inject(ctx, c); err != nil {
+		err = xerrors.Errorf("inject: %w", err)
+		endSpan(span, err)
+		return nil, err
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		b := backoff.NewConstantBackOff(time.Millisecond * 50)
+		return backoff.Retry(func() error {
+			s, err := replSetGetStatus(gCtx, shard)
+			if err != nil {
+				return err
+			}
+			primary, ok := s.primary()
+			if !ok || s.Term <= status.Term {
+				return xerrors.New("no new primary")
+			}
+			timing.ToNewPrimary = time.Since(timing.Started)
+			timing.NewPrimary = primary
+			span.AddEvent("New primary")
+			return nil
+		}, backoff.WithContext(b, gCtx))
+	})
+	g.Go(func() error {
+		coll := router.Database(c.failoverProbeDB(shardID)).Collection("probe")
+		b := backoff.NewConstantBackOff(time.Millisecond * 50)
+		return backoff.Retry(func() error {
+			writeCtx, cancel := context.WithTimeout(gCtx, time.Second)
+			defer cancel()
+			if _, err := coll.InsertOne(writeCtx, bson.M{"t": time.Now()}); err != nil {
+				return err
+			}
+			timing.ToFirstWrite = time.Since(timing.Started)
+			span.AddEvent("First write")
+			return nil
+		}, backoff.WithContext(b, gCtx))
+	})
+	if err := g.Wait(); err != nil {
+		err = xerrors.Errorf("wait: %w", err)
+		endSpan(span, err)
+		return nil, err
+	}
+	endSpan(span, nil)
+
+	c.log.Info("Failover measured",
+		zap.String("shard", timing.Shard),
+		zap.String("old_primary", timing.OldPrimary),
+		zap.String("new_primary", timing.NewPrimary),
+		zap.Duration("to_new_primary", timing.ToNewPrimary),
+		zap.Duration("to_first_write", timing.ToFirstWrite),
+	)
+
+	return timing, nil
+}
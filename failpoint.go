@@ -0,0 +1,147 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// testCommandsArgs returns arguments that enable test commands like
+// configureFailPoint.
+func testCommandsArgs() []string {
+	return []string{"--setParameter", "enableTestCommands=1"}
+}
+
+// FailPoint is server failpoint configuration, requires
+// Config.EnableTestCommands.
+//
+// See https://github.com/mongodb/mongo/wiki/The-%22failCommand%22-fail-point
+type FailPoint struct {
+	Name string
+	// Mode is "alwaysOn", "off", {"times": n}, {"skip": n} or
+	// {"activationProbability": p}.
+	Mode interface{}
+	Data bson.M
+}
+
+func (fp FailPoint) command() bson.D {
+	cmd := bson.D{
+		{Key: "configureFailPoint", Value: fp.Name},
+		{Key: "mode", Value: fp.Mode},
+	}
+	if len(fp.Data) > 0 {
+		cmd = append(cmd, bson.E{Key: "data", Value: fp.Data})
+	}
+	return cmd
+}
+
+func configureFailPoint(ctx context.Context, client *mongo.Client, fp FailPoint) error {
+	return client.Database("admin").RunCommand(ctx, fp.command()).Err()
+}
+
+// SetFailPoint configures failpoint on service.
+func (c *Cluster) SetFailPoint(ctx context.Context, name string, fp FailPoint) error {
+	uri, err := c.serviceURI(name)
+	if err != nil {
+		return err
+	}
+	return withClient(ctx, uri, func(client *mongo.Client) error {
+		if err := configureFailPoint(ctx, client, fp); err != nil {
+			return xerrors.Errorf("%s: %w", fp.Name, err)
+		}
+		return nil
+	})
+}
+
+// ClearFailPoint turns failpoint of service off.
+func (c *Cluster) ClearFailPoint(ctx context.Context, name, failPoint string) error {
+	return c.SetFailPoint(ctx, name, FailPoint{Name: failPoint, Mode: "off"})
+}
+
+// FailPointScenario is named set of failpoints configured on shard
+// primary until recovery. Primary is resolved on Inject.
+type FailPointScenario struct {
+	Name       string
+	ShardID    int
+	FailPoints []FailPoint
+
+	target string
+}
+
+func (s *FailPointScenario) String() string {
+	return fmt.Sprintf("%s on shard %d", s.Name, s.ShardID)
+}
+
+func (s *FailPointScenario) Inject(ctx context.Context, c *Cluster) error {
+	primary, err := c.shardPrimary(ctx, s.ShardID)
+	if err != nil {
+		return xerrors.Errorf("primary: %w", err)
+	}
+	s.target = primary.Name
+	for _, fp := range s.FailPoints {
+		if err := c.SetFailPoint(ctx, s.target, fp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FailPointScenario) Recover(ctx context.Context, c *Cluster) error {
+	for _, fp := range s.FailPoints {
+		if err := c.ClearFailPoint(ctx, s.target, fp.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SlowCommits delays commitTransaction on shard primary, including
+// commits sent by routers and transaction coordinator. Requires 4.4.
+func SlowCommits(shardID int, delay time.Duration) *FailPointScenario {
+	return &FailPointScenario{
+		Name:    "slow commits",
+		ShardID: shardID,
+		FailPoints: []FailPoint{{
+			Name: "failCommand",
+			Mode: "alwaysOn",
+			Data: bson.M{
+				"failCommands":         []string{"commitTransaction"},
+				"blockConnection":      true,
+				"blockTimeMS":          delay.Milliseconds(),
+				"failInternalCommands": true,
+			},
+		}},
+	}
+}
+
+// WriteConflictStorm makes storage engine operations on shard primary
+// fail with WriteConflict with given probability, so writes are retried
+// and transactions are aborted.
+func WriteConflictStorm(shardID int, probability float64) *FailPointScenario {
+	return &FailPointScenario{
+		Name:    "WriteConflict storm",
+		ShardID: shardID,
+		FailPoints: []FailPoint{{
+			Name: "WTWriteConflictException",
+			Mode: bson.M{"activationProbability": probability},
+		}},
+	}
+}
+
+// HangAfterCollectionInserts hangs inserts to collection of database on
+// shard primary after documents are inserted, until recovery.
+func HangAfterCollectionInserts(shardID int, db, collection string) *FailPointScenario {
+	return &FailPointScenario{
+		Name:    "hangAfterCollectionInserts",
+		ShardID: shardID,
+		FailPoints: []FailPoint{{
+			Name: "hangAfterCollectionInserts",
+			Mode: "alwaysOn",
+			Data: bson.M{"collectionNS": db + "." + collection},
+		}},
+	}
+}
@@ -0,0 +1,31 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFailPointCommand(t *testing.T) {
+	got := FailPoint{Name: "failCommand", Mode: "off"}.command()
+	if !reflect.DeepEqual(got, bson.D{
+		{Key: "configureFailPoint", Value: "failCommand"},
+		{Key: "mode", Value: "off"},
+	}) {
+		t.Errorf("off: %v", got)
+	}
+
+	s := SlowCommits(1, time.Second)
+	if s.ShardID != 1 || len(s.FailPoints) != 1 {
+		t.Fatalf("unexpected scenario %+v", s)
+	}
+	got = s.FailPoints[0].command()
+	if len(got) != 3 || got[2].Key != "data" {
+		t.Fatalf("no data: %v", got)
+	}
+	if data := got[2].Value.(bson.M); data["blockTimeMS"] != int64(1000) {
+		t.Errorf("unexpected data %v", data)
+	}
+}
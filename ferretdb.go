@@ -0,0 +1,70 @@
+package booga
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// ferretDBArgs returns arguments of FerretDB that stores data in SQLite
+// databases in working directory.
+func ferretDBArgs(opt serverOptions) []string {
+	return []string{
+		"--listen-addr=" + net.JoinHostPort(opt.IP, strconv.Itoa(opt.Port)),
+		"--handler=sqlite",
+		"--sqlite-url=file:./",
+		"--state-dir=.",
+		"--telemetry=disable",
+		// Parsed by logProxy, see parseJSONEntry.
+		"--log-format=json",
+	}
+}
+
+// ensureFerretDB runs FerretDB on routing server port instead of sharded
+// cluster, so routing server URI and client work as usual.
+func (c *Cluster) ensureFerretDB(ctx context.Context) error {
+	return c.runServer(ctx, serverOptions{
+		Name:       c.routingName(),
+		BaseDir:    c.dir,
+		BinaryPath: c.ferretDB,
+		Type:       FerretDBServer,
+		ShardID:    -1,
+		ReplicaID:  -1,
+
+		OnReady: func(ctx context.Context, client *mongo.Client) error {
+			for _, coll := range c.collections {
+				if len(coll.ShardKey) > 0 {
+					c.log.Warn("FerretDB does not support sharding, collection is not sharded",
+						zap.String("name", coll.Name),
+					)
+				}
+			}
+			if err := c.setupCollections(ctx, client); err != nil {
+				return xerrors.Errorf("collections: %w", err)
+			}
+			if err := c.setupGridFS(ctx, client); err != nil {
+				return xerrors.Errorf("gridfs: %w", err)
+			}
+			if err := c.setup(ctx, client); err != nil {
+				return xerrors.Errorf("OnSetup: %w", err)
+			}
+			if c.onReady != nil {
+				if err := c.onReady(ctx, client); err != nil {
+					return xerrors.Errorf("OnReady: %w", err)
+				}
+			}
+			c.startupDone(nil)
+			c.markReady()
+			c.graph.markUp(c.routingName())
+
+			return nil
+		},
+
+		IP:   "127.0.0.1",
+		Port: c.routingPort(),
+	})
+}
@@ -0,0 +1,39 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// flowControlArgs returns mongod arguments that disable flow control or
+// set its target majority commit lag, 10s by default.
+//
+// See https://docs.mongodb.com/manual/replication/#flow-control
+func flowControlArgs(disabled bool, targetLag time.Duration) []string {
+	if disabled {
+		return []string{"--setParameter", "enableFlowControl=false"}
+	}
+	if targetLag <= 0 {
+		return nil
+	}
+	secs := int(targetLag / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return []string{"--setParameter", fmt.Sprintf("flowControlTargetLagSeconds=%d", secs)}
+}
+
+// SetFlowControl enables or disables throttling of writes on primaries
+// when majority commit point lags.
+func (c *Cluster) SetFlowControl(ctx context.Context, enabled bool) error {
+	return c.eachService(ctx, DataServer, func(_ ServiceInfo, client *mongo.Client) error {
+		return client.Database("admin").RunCommand(ctx, bson.D{
+			{Key: "setParameter", Value: 1},
+			{Key: "enableFlowControl", Value: enabled},
+		}).Err()
+	})
+}
@@ -0,0 +1,51 @@
+package booga
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+func fsyncLock(ctx context.Context, client *mongo.Client) error {
+	if err := client.Database("admin").
+		RunCommand(ctx, bson.M{"fsync": 1, "lock": true}).
+		Err(); err != nil {
+		return xerrors.Errorf("fsyncLock: %w", err)
+	}
+	return nil
+}
+
+func fsyncUnlock(ctx context.Context, client *mongo.Client) error {
+	if err := client.Database("admin").
+		RunCommand(ctx, bson.M{"fsyncUnlock": 1}).
+		Err(); err != nil {
+		return xerrors.Errorf("fsyncUnlock: %w", err)
+	}
+	return nil
+}
+
+// FsyncLock flushes pending writes of node to disk and locks it for
+// writes until FsyncUnlock is called. Locks are counted, so every
+// FsyncLock call should be paired with FsyncUnlock.
+func (c *Cluster) FsyncLock(ctx context.Context, name string) error {
+	uri, err := c.serviceURI(name)
+	if err != nil {
+		return err
+	}
+	return withClient(ctx, uri, func(client *mongo.Client) error {
+		return fsyncLock(ctx, client)
+	})
+}
+
+// FsyncUnlock releases write lock acquired by FsyncLock.
+func (c *Cluster) FsyncUnlock(ctx context.Context, name string) error {
+	uri, err := c.serviceURI(name)
+	if err != nil {
+		return err
+	}
+	return withClient(ctx, uri, func(client *mongo.Client) error {
+		return fsyncUnlock(ctx, client)
+	})
+}
@@ -0,0 +1,216 @@
+package booga
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+)
+
+// FieldType is type of generated field value.
+type FieldType byte
+
+const (
+	// FieldInt is int64 in [Min, Max).
+	FieldInt FieldType = iota
+	// FieldFloat is float64 in [Min, Max).
+	FieldFloat
+	// FieldString is lowercase latin string with length in [Min, Max].
+	FieldString
+	// FieldTime is time in [From, To).
+	FieldTime
+	// FieldBool is boolean.
+	FieldBool
+	// FieldObjectID is random ObjectID.
+	FieldObjectID
+)
+
+// Field describes single generated document field.
+type Field struct {
+	Name string
+	Type FieldType
+	// Cardinality limits count of distinct values, unlimited if zero.
+	Cardinality int
+
+	Min, Max float64   // for FieldInt, FieldFloat and FieldString
+	From, To time.Time // for FieldTime
+}
+
+// Schema of generated documents.
+type Schema struct {
+	Fields []Field
+
+	// BatchSize is count of documents in single bulk write, 1000 by default.
+	BatchSize int
+	// Concurrency is count of parallel bulk writes, GOMAXPROCS by default.
+	Concurrency int
+	// Seed for random source, current time by default.
+	Seed int64
+}
+
+func (s *Schema) setDefaults() {
+	if s.BatchSize <= 0 {
+		s.BatchSize = 1000
+	}
+	if s.Concurrency <= 0 {
+		s.Concurrency = runtime.GOMAXPROCS(0)
+	}
+	if s.Seed == 0 {
+		s.Seed = time.Now().UnixNano()
+	}
+}
+
+// splitMix is cheap rand.Source64 implementation, used to derive values
+// of fields with limited cardinality.
+type splitMix struct {
+	state uint64
+}
+
+func (s *splitMix) Seed(seed int64) { s.state = uint64(seed) }
+
+func (s *splitMix) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *splitMix) Int63() int64 { return int64(s.Uint64() >> 1) }
+
+const letters = "abcdefghijklmnopqrstuvwxyz"
+
+// value generates field value using rnd.
+func (f Field) value(rnd *rand.Rand, seed int64, idx int) interface{} {
+	if f.Cardinality > 0 {
+		// Deriving value from one of Cardinality predefined states.
+		k := rnd.Intn(f.Cardinality)
+		rnd = rand.New(&splitMix{state: uint64(seed) ^ uint64(idx)<<32 ^ uint64(k)})
+	}
+
+	switch f.Type {
+	case FieldInt:
+		lo, hi := int64(f.Min), int64(f.Max)
+		if hi <= lo {
+			return lo
+		}
+		return lo + rnd.Int63n(hi-lo)
+	case FieldFloat:
+		return f.Min + rnd.Float64()*(f.Max-f.Min)
+	case FieldString:
+		lo, hi := int(f.Min), int(f.Max)
+		n := lo
+		if hi > lo {
+			n += rnd.Intn(hi - lo + 1)
+		}
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			b.WriteByte(letters[rnd.Intn(len(letters))])
+		}
+		return b.String()
+	case FieldTime:
+		d := f.To.Sub(f.From)
+		if d <= 0 {
+			return f.From
+		}
+		return f.From.Add(time.Duration(rnd.Int63n(int64(d))))
+	case FieldBool:
+		return rnd.Intn(2) == 1
+	case FieldObjectID:
+		var id primitive.ObjectID
+		rnd.Read(id[:])
+		return id
+	default:
+		return nil
+	}
+}
+
+// document generates single document.
+func (s Schema) document(rnd *rand.Rand) bson.D {
+	doc := make(bson.D, 0, len(s.Fields))
+	for i, f := range s.Fields {
+		doc = append(doc, bson.E{Key: f.Name, Value: f.value(rnd, s.Seed, i)})
+	}
+	return doc
+}
+
+// splitNamespace splits "db.collection" namespace.
+func splitNamespace(ns string) (db, coll string, err error) {
+	i := strings.IndexByte(ns, '.')
+	if i <= 0 || i == len(ns)-1 {
+		return "", "", xerrors.Errorf("invalid namespace %q", ns)
+	}
+	return ns[:i], ns[i+1:], nil
+}
+
+// Generate inserts n documents matching schema to ns namespace
+// ("db.collection") through routing server using parallel bulk writes.
+func (c *Cluster) Generate(ctx context.Context, ns string, n int, schema Schema) error {
+	schema.setDefaults()
+	dbName, collName, err := splitNamespace(ns)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.RouterClient(ctx)
+	if err != nil {
+		return xerrors.Errorf("connect: %w", err)
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	coll := client.Database(dbName).Collection(collName)
+	start := time.Now()
+
+	batches := make(chan int)
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		defer close(batches)
+		for i := 0; i < n; i += schema.BatchSize {
+			size := schema.BatchSize
+			if n-i < size {
+				size = n - i
+			}
+			select {
+			case batches <- size:
+			case <-gCtx.Done():
+				return gCtx.Err()
+			}
+		}
+		return nil
+	})
+	for w := 0; w < schema.Concurrency; w++ {
+		rnd := rand.New(rand.NewSource(schema.Seed + int64(w)))
+		g.Go(func() error {
+			for size := range batches {
+				docs := make([]mongo.WriteModel, size)
+				for i := range docs {
+					docs[i] = mongo.NewInsertOneModel().SetDocument(schema.document(rnd))
+				}
+				if _, err := coll.BulkWrite(gCtx, docs, options.BulkWrite().SetOrdered(false)); err != nil {
+					return xerrors.Errorf("bulk write: %w", err)
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	c.log.Info("Documents generated",
+		zap.String("ns", ns),
+		zap.Int("n", n),
+		zap.Duration("d", time.Since(start)),
+	)
+
+	return nil
+}
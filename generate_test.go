@@ -0,0 +1,53 @@
+package booga
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSchemaDocument(t *testing.T) {
+	from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := Schema{
+		Seed: 1,
+		Fields: []Field{
+			{Name: "n", Type: FieldInt, Min: 10, Max: 20},
+			{Name: "s", Type: FieldString, Min: 3, Max: 5},
+			{Name: "t", Type: FieldTime, From: from, To: from.Add(time.Hour)},
+			{Name: "k", Type: FieldInt, Min: 0, Max: 1 << 40, Cardinality: 3},
+		},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	distinct := map[int64]struct{}{}
+	for i := 0; i < 1000; i++ {
+		doc := s.document(rnd).Map()
+		if n := doc["n"].(int64); n < 10 || n >= 20 {
+			t.Fatalf("n out of range: %d", n)
+		}
+		if str := doc["s"].(string); len(str) < 3 || len(str) > 5 {
+			t.Fatalf("s length out of range: %q", str)
+		}
+		if ts := doc["t"].(time.Time); ts.Before(from) || !ts.Before(from.Add(time.Hour)) {
+			t.Fatalf("t out of range: %s", ts)
+		}
+		distinct[doc["k"].(int64)] = struct{}{}
+	}
+	if len(distinct) != 3 {
+		t.Errorf("expected 3 distinct values, got %d", len(distinct))
+	}
+}
+
+func TestSplitNamespace(t *testing.T) {
+	db, coll, err := splitNamespace("cloud.users.archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db != "cloud" || coll != "users.archive" {
+		t.Errorf("unexpected %q %q", db, coll)
+	}
+	for _, ns := range []string{"", "cloud", ".users", "cloud."} {
+		if _, _, err := splitNamespace(ns); err == nil {
+			t.Errorf("%q: expected error", ns)
+		}
+	}
+}
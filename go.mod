@@ -5,6 +5,9 @@ go 1.16
 require (
 	github.com/cenkalti/backoff/v4 v4.1.0
 	go.mongodb.org/mongo-driver v1.4.6
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+	go.uber.org/multierr v1.5.0
 	go.uber.org/zap v1.16.0
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543
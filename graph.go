@@ -0,0 +1,193 @@
+package booga
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+)
+
+// graphNode is node of dependencyGraph, e.g. replica set, routing server
+// or sidecar.
+type graphNode struct {
+	deps []string
+	run  func(ctx context.Context) error
+
+	up     chan struct{} // closed when node is up
+	upOnce sync.Once
+	done   chan struct{} // closed when run returns
+}
+
+// dependencyGraph is graph of cluster nodes by name. Node is run when
+// every dependency is up, so independent nodes start in parallel, and
+// nodes are stopped in reverse order. Zero value is ready to use.
+type dependencyGraph struct {
+	mux   sync.Mutex
+	nodes map[string]*graphNode
+}
+
+// add adds node that is run by f after deps are up. Node with the same
+// name can be replaced only after its run returned.
+func (g *dependencyGraph) add(name string, deps []string, f func(ctx context.Context) error) error {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	if g.nodes == nil {
+		g.nodes = map[string]*graphNode{}
+	}
+	if prev, ok := g.nodes[name]; ok {
+		select {
+		case <-prev.done:
+		default:
+			return xerrors.Errorf("node %s already exists", name)
+		}
+	}
+	g.nodes[name] = &graphNode{
+		deps: deps,
+		run:  f,
+		up:   make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	return nil
+}
+
+// remove removes node that was never run.
+func (g *dependencyGraph) remove(name string) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	delete(g.nodes, name)
+}
+
+func (g *dependencyGraph) node(name string) (*graphNode, bool) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	n, ok := g.nodes[name]
+	return n, ok
+}
+
+// markUp marks node as up, so dependent nodes can start.
+func (g *dependencyGraph) markUp(name string) {
+	if n, ok := g.node(name); ok {
+		n.upOnce.Do(func() { close(n.up) })
+	}
+}
+
+// waitUp blocks until node is up. Returns error if node stopped before.
+func (g *dependencyGraph) waitUp(ctx context.Context, name string) error {
+	n, ok := g.node(name)
+	if !ok {
+		return xerrors.Errorf("no node %s", name)
+	}
+
+	select {
+	case <-n.up:
+		return nil
+	case <-n.done:
+		select {
+		case <-n.up:
+			return nil
+		default:
+			return xerrors.Errorf("%s stopped before it was up", name)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// levels returns node names grouped by depth: nodes of first level have
+// no dependencies and every other node depends on node of previous level.
+// Returns error on unknown dependency or cycle.
+func (g *dependencyGraph) levels() ([][]string, error) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+
+	pending := map[string]int{} // count of unresolved dependencies
+	dependents := map[string][]string{}
+	for name, n := range g.nodes {
+		pending[name] = len(n.deps)
+		for _, dep := range n.deps {
+			if _, ok := g.nodes[dep]; !ok {
+				return nil, xerrors.Errorf("%s depends on unknown %s", name, dep)
+			}
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	var (
+		levels [][]string
+		level  []string
+	)
+	for name, count := range pending {
+		if count == 0 {
+			level = append(level, name)
+		}
+	}
+	resolved := 0
+	for len(level) > 0 {
+		sort.Strings(level)
+		levels = append(levels, level)
+		resolved += len(level)
+
+		var next []string
+		for _, name := range level {
+			for _, dependent := range dependents[name] {
+				pending[dependent]--
+				if pending[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		level = next
+	}
+	if resolved < len(pending) {
+		var cycle []string
+		for name, count := range pending {
+			if count > 0 {
+				cycle = append(cycle, name)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, xerrors.Errorf("dependency cycle between %v", cycle)
+	}
+
+	return levels, nil
+}
+
+// start runs node in group after its dependencies are up.
+func (g *dependencyGraph) start(ctx context.Context, eg *errgroup.Group, name string) error {
+	n, ok := g.node(name)
+	if !ok {
+		return xerrors.Errorf("no node %s", name)
+	}
+
+	eg.Go(func() error {
+		defer close(n.done)
+		for _, dep := range n.deps {
+			if err := g.waitUp(ctx, dep); err != nil {
+				return xerrors.Errorf("%s: wait %s: %w", name, dep, err)
+			}
+		}
+		return n.run(ctx)
+	})
+	return nil
+}
+
+// startAll checks graph and runs every node in group.
+func (g *dependencyGraph) startAll(ctx context.Context, eg *errgroup.Group) error {
+	levels, err := g.levels()
+	if err != nil {
+		return err
+	}
+	for _, level := range levels {
+		for _, name := range level {
+			if err := g.start(ctx, eg, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package booga
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func TestDependencyGraphLevels(t *testing.T) {
+	var g dependencyGraph
+	nop := func(ctx context.Context) error { return nil }
+	for name, deps := range map[string][]string{
+		"rsConfig": nil,
+		"rsData0":  {"rsConfig"},
+		"rsData1":  {"rsConfig"},
+		"routing":  {"rsData0", "rsData1"},
+		"app":      {"routing", "kms"},
+		"kms":      nil,
+	} {
+		if err := g.add(name, deps, nop); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.add("kms", nil, nop); err == nil {
+		t.Error("node replaced before run")
+	}
+
+	levels, err := g.levels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{
+		{"kms", "rsConfig"},
+		{"rsData0", "rsData1"},
+		{"routing"},
+		{"app"},
+	}
+	if !reflect.DeepEqual(levels, expected) {
+		t.Errorf("unexpected levels %v", levels)
+	}
+
+	if err := g.add("proxy", []string{"missing"}, nop); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.levels(); err == nil {
+		t.Error("expected error for unknown dependency")
+	}
+	g.remove("proxy")
+
+	if err := g.add("a", []string{"b"}, nop); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.add("b", []string{"a"}, nop); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.levels(); err == nil {
+		t.Error("expected error for cycle")
+	}
+}
+
+func TestDependencyGraphStart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	var (
+		g     dependencyGraph
+		mux   sync.Mutex
+		order []string
+	)
+	node := func(name string, up bool) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			mux.Lock()
+			order = append(order, name)
+			mux.Unlock()
+			if up {
+				g.markUp(name)
+			}
+			return nil
+		}
+	}
+	if err := g.add("routing", []string{"rsConfig"}, node("routing", true)); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.add("rsConfig", nil, node("rsConfig", true)); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.add("job", nil, node("job", false)); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.add("app", []string{"job"}, node("app", true)); err != nil {
+		t.Fatal(err)
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	if err := g.startAll(egCtx, eg); err != nil {
+		t.Fatal(err)
+	}
+	if err := eg.Wait(); err == nil {
+		t.Fatal("expected error for dependency that stopped before it was up")
+	}
+	if err := g.waitUp(ctx, "rsConfig"); err != nil {
+		t.Fatal(err)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	for _, name := range order {
+		if name == "app" {
+			t.Error("app started without dependency")
+		}
+	}
+}
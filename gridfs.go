@@ -0,0 +1,124 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// GridFSBucket describes GridFS bucket that is seeded with files from
+// local directory before OnSetup is called.
+type GridFSBucket struct {
+	// Name of bucket, "fs" by default.
+	Name string
+	// Dir is local directory that is uploaded recursively. File names in
+	// bucket are slash-separated paths relative to Dir.
+	Dir string
+}
+
+func (b GridFSBucket) name() string {
+	if b.Name == "" {
+		return options.DefaultName
+	}
+	return b.Name
+}
+
+func uploadFile(bucket *gridfs.Bucket, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return xerrors.Errorf("open: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := bucket.UploadFromStream(name, f); err != nil {
+		return xerrors.Errorf("upload: %w", err)
+	}
+
+	return nil
+}
+
+// writeDigest writes name, size and modification time of every file from
+// b.Dir to w, so changed files change digest.
+func (b GridFSBucket) writeDigest(w io.Writer) error {
+	return filepath.Walk(b.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(b.Dir, path)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s:%d:%d\n", filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano())
+		return err
+	})
+}
+
+// seedBucket uploads every file from b.Dir to bucket.
+func (c *Cluster) seedBucket(ctx context.Context, db *mongo.Database, b GridFSBucket) error {
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(b.name()))
+	if err != nil {
+		return xerrors.Errorf("bucket: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := bucket.SetWriteDeadline(deadline); err != nil {
+			return xerrors.Errorf("deadline: %w", err)
+		}
+	}
+
+	var files int
+	if err := filepath.Walk(b.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(b.Dir, path)
+		if err != nil {
+			return err
+		}
+		if err := uploadFile(bucket, filepath.ToSlash(rel), path); err != nil {
+			return xerrors.Errorf("%s: %w", rel, err)
+		}
+		files++
+
+		return nil
+	}); err != nil {
+		return xerrors.Errorf("walk: %w", err)
+	}
+
+	c.log.Info("Bucket seeded",
+		zap.String("name", b.name()),
+		zap.String("dir", b.Dir),
+		zap.Int("files", files),
+	)
+
+	return nil
+}
+
+// setupGridFS seeds GridFS buckets from configuration.
+func (c *Cluster) setupGridFS(ctx context.Context, client *mongo.Client) error {
+	db := client.Database(c.db)
+	for _, b := range c.gridFS {
+		if err := c.seedBucket(ctx, db, b); err != nil {
+			return xerrors.Errorf("seed %s: %w", b.name(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package booga
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGridFSDigest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "booga-gridfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if err := os.MkdirAll(filepath.Join(dir, "img"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "img", "logo.png")
+	if err := ioutil.WriteFile(name, []byte("logo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	b := GridFSBucket{Dir: dir}
+	digest := func() string {
+		var buf bytes.Buffer
+		if err := b.writeDigest(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	first := digest()
+	if !bytes.Contains([]byte(first), []byte("img/logo.png:4:")) {
+		t.Errorf("unexpected digest %q", first)
+	}
+	if digest() != first {
+		t.Error("digest is not stable")
+	}
+	if err := ioutil.WriteFile(name, []byte("new logo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if digest() == first {
+		t.Error("digest is not changed")
+	}
+}
@@ -0,0 +1,201 @@
+package booga
+
+import (
+	"context"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// lifecycle is state of cluster started by Start.
+type lifecycle struct {
+	mux     sync.Mutex
+	started bool
+	cancel  context.CancelFunc
+	done    chan struct{} // closed when Run returns
+	err     error         // result of Run, set before done is closed
+	stopped bool          // Stop was called
+}
+
+// markReady signals that cluster is ready.
+func (c *Cluster) markReady() {
+	c.readyOnce.Do(func() { close(c.ready) })
+}
+
+// Start starts cluster in background and returns when cluster is ready
+// or failed to start. Context is used only for startup.
+//
+// Started cluster should be stopped by Stop.
+func (c *Cluster) Start(ctx context.Context) error {
+	c.lifecycle.mux.Lock()
+	if c.lifecycle.started {
+		c.lifecycle.mux.Unlock()
+		return xerrors.New("already started")
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	c.lifecycle.started = true
+	c.lifecycle.cancel = cancel
+	c.lifecycle.done = make(chan struct{})
+	c.lifecycle.mux.Unlock()
+
+	go func() {
+		defer close(c.lifecycle.done)
+		c.lifecycle.err = c.Run(runCtx)
+	}()
+
+	select {
+	case <-c.ready:
+		return nil
+	case <-c.lifecycle.done:
+		return xerrors.Errorf("run: %w", c.lifecycle.err)
+	case <-ctx.Done():
+		cancel()
+		<-c.lifecycle.done
+		return ctx.Err()
+	}
+}
+
+// Wait blocks until cluster started by Start exits and returns its error.
+// Wait returns nil if cluster was stopped by Stop.
+func (c *Cluster) Wait() error {
+	c.lifecycle.mux.Lock()
+	started, done := c.lifecycle.started, c.lifecycle.done
+	c.lifecycle.mux.Unlock()
+	if !started {
+		return xerrors.New("not started")
+	}
+
+	<-done
+
+	c.lifecycle.mux.Lock()
+	defer c.lifecycle.mux.Unlock()
+
+	if c.lifecycle.stopped && xerrors.Is(c.lifecycle.err, context.Canceled) {
+		return nil
+	}
+	return c.lifecycle.err
+}
+
+// stopStageTimeout is time given to services of single shutdown stage to
+// exit gracefully, e.g. for primary to wait for secondaries to catch up.
+const stopStageTimeout = time.Second * 20
+
+// terminate sends SIGTERM to running services in reverse dependency
+// order, so mongo can shut down gracefully: sidecars stop before routing
+// server, routing server before shards and shards before config server.
+// Services of independent nodes are terminated together.
+//
+// Every stage has own budget of stopStageTimeout, services that don't
+// exit in time are killed and shutdown proceeds to next stage.
+func (c *Cluster) terminate(ctx context.Context) error {
+	var errs error
+	for _, names := range c.shutdownStages() {
+		var terminated []string
+		for _, name := range names {
+			c.services.with(name, func(s *service) {
+				if s.process == nil {
+					return
+				}
+				s.terminated = true
+				terminated = append(terminated, name)
+				if err := signalGroup(s.process, syscall.SIGTERM); err != nil && err != os.ErrProcessDone {
+					c.log.Warn("Failed to terminate", zap.String("name", s.info.Name), zap.Error(err))
+					_ = signalGroup(s.process, syscall.SIGKILL)
+				}
+			})
+		}
+		if err := c.waitTerminated(ctx, terminated); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+		if ctx.Err() != nil {
+			return errs
+		}
+	}
+
+	return errs
+}
+
+// waitTerminated waits up to stopStageTimeout until services exit and
+// kills services that are still running after that.
+func (c *Cluster) waitTerminated(ctx context.Context, names []string) error {
+	ctx, cancel := context.WithTimeout(ctx, stopStageTimeout)
+	defer cancel()
+
+	var errs error
+	for _, name := range names {
+		if err := c.services.waitFor(ctx, name, func(state ServiceState, registered bool) (bool, error) {
+			return !registered || state == ServiceStopped, nil
+		}); err != nil {
+			c.services.with(name, func(s *service) {
+				if s.process != nil {
+					_ = signalGroup(s.process, syscall.SIGKILL)
+				}
+			})
+			errs = multierr.Append(errs, xerrors.Errorf("wait %s: %w", name, err))
+		}
+	}
+	return errs
+}
+
+// shutdownStages returns names of running services grouped in reverse
+// dependency order. Services that are not in dependency graph are
+// stopped first.
+func (c *Cluster) shutdownStages() [][]string {
+	levels, err := c.graph.levels()
+	if err != nil {
+		// Graph is checked on startup.
+		levels = nil
+	}
+	depth := map[string]int{}
+	for i, level := range levels {
+		for _, node := range level {
+			depth[node] = i
+		}
+	}
+
+	stages := make([][]string, len(levels)+1)
+	for _, s := range c.Services() {
+		if s.State == ServiceStopped {
+			continue
+		}
+		d, ok := depth[c.graphNodeOf(s)]
+		if !ok {
+			d = len(levels)
+		}
+		// Deepest nodes are stopped first.
+		stage := len(levels) - d
+		stages[stage] = append(stages[stage], s.Name)
+	}
+	return stages
+}
+
+// Stop gracefully stops cluster started by Start and waits until every
+// service exits. If ctx is done before that, services are killed.
+func (c *Cluster) Stop(ctx context.Context) error {
+	c.lifecycle.mux.Lock()
+	if !c.lifecycle.started {
+		c.lifecycle.mux.Unlock()
+		return xerrors.New("not started")
+	}
+	c.lifecycle.stopped = true
+	cancel, done := c.lifecycle.cancel, c.lifecycle.done
+	c.lifecycle.mux.Unlock()
+
+	if err := c.terminate(ctx); err != nil {
+		c.log.Warn("Graceful stop timed out, killing services", zap.Error(err))
+	} else {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			c.log.Warn("Graceful stop timed out, killing services")
+		}
+	}
+	cancel()
+
+	return c.Wait()
+}
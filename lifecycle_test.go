@@ -0,0 +1,101 @@
+//go:build !windows
+// +build !windows
+
+package booga
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestTerminateStaged(t *testing.T) {
+	c := New(Config{Shards: 2, Replicas: 1, Log: zap.NewNop()})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "booga-stop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	stopped := filepath.Join(dir, "stopped")
+
+	nop := func(ctx context.Context) error { return nil }
+	for name, deps := range map[string][]string{
+		c.configReplicaSet(): nil,
+		c.shardReplicaSet(0): {c.configReplicaSet()},
+		c.shardReplicaSet(1): {c.configReplicaSet()},
+		c.routingName():      {c.shardReplicaSet(0), c.shardReplicaSet(1)},
+		"app":                {c.routingName()},
+	} {
+		if err := c.graph.add(name, deps, nop); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every service records its name on graceful stop.
+	services := []ServiceInfo{
+		{Name: c.configName(), Type: ConfigServer, ShardID: -1},
+		{Name: c.dataName(0, 0), Type: DataServer, ShardID: 0},
+		{Name: c.dataName(1, 0), Type: DataServer, ShardID: 1},
+		{Name: c.routingName(), Type: RoutingServer, ShardID: -1, ReplicaID: -1},
+		{Name: "app", Type: SidecarServer, ShardID: -1, ReplicaID: -1},
+	}
+	for _, info := range services {
+		info.State = ServiceStarting
+		if err := c.services.add(&service{info: info}); err != nil {
+			t.Fatal(err)
+		}
+		started := filepath.Join(dir, info.Name)
+		cmd := exec.Command("sh", "-c", `trap 'echo "$0" >> "$1"; exit 0' TERM; : > "$2"; while :; do sleep 0.05; done`, info.Name, stopped, started)
+		setProcessGroup(cmd)
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		c.setProcess(info.Name, cmd.Process)
+		// Signal is handled after trap is set.
+		for !isFile(started) {
+			time.Sleep(time.Millisecond * 10)
+		}
+		c.setReady(info.Name)
+		name := info.Name
+		go func() {
+			err := cmd.Wait()
+			c.setExited(name, cmd.ProcessState.ExitCode(), err)
+		}()
+	}
+
+	if err := c.terminate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range c.Services() {
+		if s.State != ServiceStopped || s.ExitCode != 0 {
+			t.Errorf("%s is %s with code %d", s.Name, s.State, s.ExitCode)
+		}
+	}
+
+	data, err := ioutil.ReadFile(stopped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := strings.Fields(string(data))
+	if len(order) != len(services) {
+		t.Fatalf("unexpected stop order %v", order)
+	}
+	// Shards are stopped together, in any order.
+	sort.Strings(order[2:4])
+	expected := []string{"app", c.routingName(), c.dataName(0, 0), c.dataName(1, 0), c.configName()}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("unexpected stop order %v", order)
+	}
+}
@@ -0,0 +1,59 @@
+package booga
+
+import (
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// recommendedFileLimit is open file descriptors limit recommended for
+// mongod, lower limit produces startup warning.
+const recommendedFileLimit = 64000
+
+// minFileLimit is open file descriptors limit below which services of even
+// small cluster run out of descriptors for data files and connections.
+const minFileLimit = 256
+
+// serviceCount returns count of services of initial topology.
+func (c *Cluster) serviceCount() int {
+	if c.ferretDB != "" {
+		return 1 + len(c.sidecars)
+	}
+	n := 2 + len(c.sidecars) // config server and router
+	for shardID := 0; shardID < c.shards; shardID++ {
+		n += c.shardReplicas(shardID)
+	}
+	return n
+}
+
+// ensureFileLimit selects open file descriptors limit of services, up to
+// recommended one and not above hard limit, and returns error if limit
+// is too low to run services at all. Limit is applied to services only,
+// see startWithLimits.
+func (c *Cluster) ensureFileLimit() error {
+	hard, err := hardFileLimit()
+	if err != nil {
+		return xerrors.Errorf("get: %w", err)
+	}
+	if hard == 0 {
+		// Limit is not managed on this platform.
+		return nil
+	}
+	limit := hard
+	if limit > recommendedFileLimit {
+		limit = recommendedFileLimit
+	}
+	if limit < minFileLimit {
+		return xerrors.Errorf("open files hard limit %d is below %d: raise hard limit, e.g. ulimit -Hn %d",
+			limit, minFileLimit, recommendedFileLimit,
+		)
+	}
+	if limit < recommendedFileLimit {
+		c.log.Warn("Open files limit is below recommended",
+			zap.Uint64("limit", limit),
+			zap.Uint64("recommended", recommendedFileLimit),
+			zap.Int("services", c.serviceCount()),
+		)
+	}
+	c.fileLimit = limit
+	return nil
+}
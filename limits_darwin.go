@@ -0,0 +1,12 @@
+package booga
+
+// darwinMaxFiles is maximum open files limit accepted by setrlimit on
+// darwin, where hard limit is usually unlimited.
+const darwinMaxFiles = 10240
+
+func capFileLimit(hard uint64) uint64 {
+	if hard > darwinMaxFiles {
+		return darwinMaxFiles
+	}
+	return hard
+}
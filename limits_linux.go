@@ -0,0 +1,7 @@
+package booga
+
+// capFileLimit returns hard limit as is, setrlimit accepts any value up to
+// hard limit on linux.
+func capFileLimit(hard uint64) uint64 {
+	return hard
+}
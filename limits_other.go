@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package booga
+
+import "os/exec"
+
+// hardFileLimit returns zero, open file descriptors limit is not managed
+// on this platform.
+func hardFileLimit() (uint64, error) {
+	return 0, nil
+}
+
+// startWithLimits starts cmd, resource limits of services are not managed
+// on this platform.
+func startWithLimits(cmd *exec.Cmd, files uint64, core bool) error {
+	return cmd.Start()
+}
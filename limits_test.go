@@ -0,0 +1,65 @@
+package booga
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestServiceCount(t *testing.T) {
+	c := New(Config{
+		Shards:     2,
+		ShardSpecs: []ShardSpec{{Replicas: 3}, {Replicas: 1, Analytics: true}},
+	})
+	if got := c.serviceCount(); got != 7 {
+		t.Errorf("got %d services", got)
+	}
+	if got := New(Config{FerretDB: "ferretdb"}).serviceCount(); got != 1 {
+		t.Errorf("got %d services of FerretDB", got)
+	}
+}
+
+func TestStartWithLimits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Resource limits are not managed on windows")
+	}
+	hard, err := hardFileLimit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	limit := uint64(minFileLimit)
+	if hard < limit {
+		t.Skipf("Hard limit %d is too low", hard)
+	}
+
+	run := func(files uint64, core bool) []string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", "ulimit -S -n; ulimit -S -c; ulimit -H -c")
+		cmd.Stdout = &out
+		if err := startWithLimits(cmd, files, core); err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Fields(out.String())
+	}
+
+	before := run(0, false)
+	got := run(limit, true)
+	if got[0] != strconv.FormatUint(limit, 10) {
+		t.Errorf("got open files limit %s", got[0])
+	}
+	if got[1] != got[2] {
+		t.Errorf("got core file size limit %s, hard limit %s", got[1], got[2])
+	}
+	// Limits of current process are restored.
+	if after := run(0, false); !reflect.DeepEqual(after, before) {
+		t.Errorf("limits changed from %v to %v", before, after)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package booga
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"go.uber.org/multierr"
+)
+
+// hardFileLimit returns hard limit of open file descriptors, up to which
+// services can raise soft limit.
+func hardFileLimit() (uint64, error) {
+	var lim syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &lim); err != nil {
+		return 0, err
+	}
+	return capFileLimit(lim.Max), nil
+}
+
+// limitsMux serializes changes of resource limits of current process, so
+// concurrent starts of services don't restore limits set for each other.
+var limitsMux sync.Mutex
+
+// setSoftLimit sets soft limit of resource of current process and returns
+// function that restores previous limit.
+func setSoftLimit(resource int, set func(lim *syscall.Rlimit)) (func() error, error) {
+	var lim syscall.Rlimit
+	if err := syscall.Getrlimit(resource, &lim); err != nil {
+		return nil, err
+	}
+	prev := lim
+	set(&lim)
+	if err := syscall.Setrlimit(resource, &lim); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return syscall.Setrlimit(resource, &prev)
+	}, nil
+}
+
+// startWithLimits starts cmd with soft limit of open file descriptors set
+// to files if not zero, and with soft limit of core file size raised to
+// hard limit if core is set.
+//
+// Child process inherits limits of current process, so limits are set
+// only while child is started and restored right after. Since Go 1.21
+// explicit Setrlimit of open files makes children inherit it instead of
+// limit that process was started with.
+func startWithLimits(cmd *exec.Cmd, files uint64, core bool) (err error) {
+	if files == 0 && !core {
+		return cmd.Start()
+	}
+
+	limitsMux.Lock()
+	defer limitsMux.Unlock()
+
+	var restore []func() error
+	defer func() {
+		for _, f := range restore {
+			if restoreErr := f(); restoreErr != nil {
+				err = multierr.Append(err, restoreErr)
+			}
+		}
+	}()
+	if files > 0 {
+		f, err := setSoftLimit(syscall.RLIMIT_NOFILE, func(lim *syscall.Rlimit) { lim.Cur = files })
+		if err != nil {
+			return err
+		}
+		restore = append(restore, f)
+	}
+	if core {
+		f, err := setSoftLimit(syscall.RLIMIT_CORE, func(lim *syscall.Rlimit) { lim.Cur = lim.Max })
+		if err != nil {
+			return err
+		}
+		restore = append(restore, f)
+	}
+
+	return cmd.Start()
+}
@@ -0,0 +1,216 @@
+package booga
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+)
+
+// LoadOptions configures workload generated by Cluster.Load.
+type LoadOptions struct {
+	// Collection in cluster database, "load" by default.
+	Collection string
+	// DocumentSize is payload size of written documents in bytes,
+	// 1024 by default.
+	DocumentSize int
+	// ReadRatio is fraction of read operations in [0, 1].
+	ReadRatio float64
+	// Concurrency is count of parallel workers, 1 by default.
+	Concurrency int
+	// Duration of load.
+	Duration time.Duration
+	// Seed for random source, current time by default.
+	Seed int64
+}
+
+func (o *LoadOptions) setDefaults() {
+	if o.Collection == "" {
+		o.Collection = "load"
+	}
+	if o.DocumentSize <= 0 {
+		o.DocumentSize = 1024
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 1
+	}
+	if o.Seed == 0 {
+		o.Seed = time.Now().UnixNano()
+	}
+}
+
+// OpStats describes latencies of single operation type.
+type OpStats struct {
+	Count      int
+	Errors     int
+	Throughput float64 // operations per second
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+// LoadReport is result of Cluster.Load.
+type LoadReport struct {
+	Duration time.Duration
+	Reads    OpStats
+	Writes   OpStats
+}
+
+// percentile returns p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+func newOpStats(latencies []time.Duration, errors int, d time.Duration) OpStats {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	s := OpStats{
+		Count:  len(latencies),
+		Errors: errors,
+		P50:    percentile(latencies, 0.5),
+		P90:    percentile(latencies, 0.9),
+		P99:    percentile(latencies, 0.99),
+		Max:    percentile(latencies, 1),
+	}
+	if d > 0 {
+		s.Throughput = float64(s.Count) / d.Seconds()
+	}
+	return s
+}
+
+// loadWorker is state of single load goroutine.
+type loadWorker struct {
+	coll    *mongo.Collection
+	rnd     *rand.Rand
+	opt     LoadOptions
+	ids     []primitive.ObjectID
+	payload []byte
+
+	reads, writes           []time.Duration
+	readErrors, writeErrors int
+}
+
+func (w *loadWorker) step(ctx context.Context) {
+	if len(w.ids) > 0 && w.rnd.Float64() < w.opt.ReadRatio {
+		id := w.ids[w.rnd.Intn(len(w.ids))]
+		start := time.Now()
+		err := w.coll.FindOne(ctx, bson.M{"_id": id}).Err()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			w.readErrors++
+			return
+		}
+		w.reads = append(w.reads, time.Since(start))
+		return
+	}
+
+	w.rnd.Read(w.payload)
+	id := primitive.NewObjectID()
+	start := time.Now()
+	_, err := w.coll.InsertOne(ctx, bson.M{"_id": id, "payload": w.payload})
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		w.writeErrors++
+		return
+	}
+	w.writes = append(w.writes, time.Since(start))
+	w.ids = append(w.ids, id)
+}
+
+// Load runs read/write workload against routing server for opt.Duration
+// and reports throughput and latency percentiles.
+func (c *Cluster) Load(ctx context.Context, opt LoadOptions) (*LoadReport, error) {
+	opt.setDefaults()
+	if opt.Duration <= 0 {
+		return nil, xerrors.New("duration should be positive")
+	}
+
+	client, err := c.RouterClient(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("connect: %w", err)
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	coll := client.Database(c.db).Collection(opt.Collection)
+	workers := make([]*loadWorker, opt.Concurrency)
+	for i := range workers {
+		workers[i] = &loadWorker{
+			coll:    coll,
+			rnd:     rand.New(rand.NewSource(opt.Seed + int64(i))),
+			opt:     opt,
+			payload: make([]byte, opt.DocumentSize),
+		}
+	}
+
+	c.log.Info("Load started",
+		zap.Int("concurrency", opt.Concurrency),
+		zap.Float64("read_ratio", opt.ReadRatio),
+		zap.Duration("duration", opt.Duration),
+	)
+
+	loadCtx, cancel := context.WithTimeout(ctx, opt.Duration)
+	defer cancel()
+
+	start := time.Now()
+	g, gCtx := errgroup.WithContext(loadCtx)
+	for _, w := range workers {
+		w := w
+		g.Go(func() error {
+			for gCtx.Err() == nil {
+				w.step(gCtx)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	d := time.Since(start)
+
+	var (
+		reads, writes           []time.Duration
+		readErrors, writeErrors int
+	)
+	for _, w := range workers {
+		reads = append(reads, w.reads...)
+		writes = append(writes, w.writes...)
+		readErrors += w.readErrors
+		writeErrors += w.writeErrors
+	}
+	report := &LoadReport{
+		Duration: d,
+		Reads:    newOpStats(reads, readErrors, d),
+		Writes:   newOpStats(writes, writeErrors, d),
+	}
+
+	c.log.Info("Load finished",
+		zap.Float64("reads_per_sec", report.Reads.Throughput),
+		zap.Float64("writes_per_sec", report.Writes.Throughput),
+		zap.Duration("read_p99", report.Reads.P99),
+		zap.Duration("write_p99", report.Writes.P99),
+	)
+
+	return report, nil
+}
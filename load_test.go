@@ -0,0 +1,30 @@
+package booga
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	for _, tt := range []struct {
+		P      float64
+		Result time.Duration
+	}{
+		{P: 0.5, Result: 50},
+		{P: 0.9, Result: 90},
+		{P: 0.99, Result: 99},
+		{P: 1, Result: 100},
+		{P: 0, Result: 1},
+	} {
+		if got := percentile(sorted, tt.P); got != tt.Result {
+			t.Errorf("p%v: got %v, expected %v", tt.P, got, tt.Result)
+		}
+	}
+	if percentile(nil, 0.5) != 0 {
+		t.Error("empty should be zero")
+	}
+}
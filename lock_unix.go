@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package booga
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLock tries to acquire exclusive lock on file without blocking.
+func tryLock(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func unlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,15 @@
+package booga
+
+import (
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+func tryLock(f *os.File) (bool, error) {
+	return false, xerrors.New("file locks are not supported on windows")
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
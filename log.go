@@ -2,9 +2,12 @@ package booga
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"io"
+	"regexp"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -12,10 +15,10 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-// entry represents single mongo log entry.
+// Entry represents single mongo log entry.
 //
 // See https://docs.mongodb.com/manual/reference/log-messages/
-type entry struct {
+type Entry struct {
 	Severity   string                 `json:"s"`
 	System     string                 `json:"c"`
 	ID         int                    `json:"id"`
@@ -29,7 +32,7 @@ type entry struct {
 }
 
 // Log writes entry to zap logger as structured log entry.
-func (e *entry) Log(log *zap.Logger) {
+func (e *Entry) Log(log *zap.Logger) {
 	var severity zapcore.Level
 	switch e.Severity {
 	case "W":
@@ -37,6 +40,11 @@ func (e *entry) Log(log *zap.Logger) {
 	case "E", "F":
 		// We can't use Fatal level because this will call os.Exit.
 		severity = zapcore.ErrorLevel
+	default:
+		if strings.HasPrefix(e.Severity, "D") {
+			// Debug entries are D1-D5 by verbosity.
+			severity = zapcore.DebugLevel
+		}
 	}
 	if ce := log.Check(severity, e.Message); ce != nil {
 		// We ignore time field here.
@@ -52,11 +60,122 @@ func (e *entry) Log(log *zap.Logger) {
 	}
 }
 
+// legacyEntryRe matches plaintext log line of mongo before 4.4, e.g.
+// 2020-01-02T15:04:05.000+0300 I  NETWORK  [conn3] end connection
+var legacyEntryRe = regexp.MustCompile(`^(\S+)\s+([IWEF]|D\d?)\s+(\S+)\s+\[([^\]]*)\]\s?(.*)$`)
+
+// legacyTimeLayout is timestamp layout of plaintext log line.
+const legacyTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// parseLegacyEntry parses plaintext log line. Lines that don't match
+// format, e.g. startup messages, are returned as informational entries.
+func parseLegacyEntry(line string) Entry {
+	m := legacyEntryRe.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{Severity: "I", Message: line}
+	}
+	t, err := time.Parse(legacyTimeLayout, m[1])
+	if err != nil {
+		return Entry{Severity: "I", Message: line}
+	}
+	e := Entry{
+		Severity: m[2],
+		System:   m[3],
+		Context:  m[4],
+		Message:  m[5],
+	}
+	if e.System == "-" {
+		e.System = ""
+	}
+	e.T.Date = t
+	return e
+}
+
+// parseJSONEntry parses structured log line of mongo or FerretDB.
+func parseJSONEntry(line []byte) (Entry, error) {
+	var e Entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return e, err
+	}
+	if e.Severity != "" {
+		return e, nil
+	}
+
+	// FerretDB entries have "level" instead of "s" and keep attributes at
+	// top level.
+	var fields map[string]interface{}
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return e, err
+	}
+	level, _ := fields["level"].(string)
+	switch strings.ToLower(level) {
+	case "debug":
+		e.Severity = "D1"
+	case "warn", "warning":
+		e.Severity = "W"
+	case "error":
+		e.Severity = "E"
+	case "dpanic", "panic", "fatal":
+		e.Severity = "F"
+	default:
+		e.Severity = "I"
+	}
+	for _, k := range []string{"name", "logger"} {
+		if v, ok := fields[k].(string); ok && e.System == "" {
+			e.System = v
+		}
+	}
+	for _, k := range []string{"time", "ts"} {
+		if v, ok := fields[k].(string); ok && e.T.Date.IsZero() {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				e.T.Date = t
+			}
+		}
+	}
+	for _, k := range []string{"level", "msg", "name", "logger", "time", "ts", "caller"} {
+		delete(fields, k)
+	}
+	if len(fields) > 0 {
+		e.Attributes = fields
+	}
+	return e, nil
+}
+
+// levelCore filters entries of wrapped core by level that can be changed
+// at runtime.
+type levelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func (c levelCore) Enabled(l zapcore.Level) bool {
+	return c.level.Enabled(l) && c.Core.Enabled(l)
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c levelCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(e.Level) {
+		return ce
+	}
+	return c.Core.Check(e, ce)
+}
+
+// withLevel returns logger that additionally filters entries by level.
+func withLevel(log *zap.Logger, level zap.AtomicLevel) *zap.Logger {
+	return log.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return levelCore{Core: core, level: level}
+	}))
+}
+
 // logProxy returns io.Writer that can be used as mongo log output.
 //
-// The io.Writer will parse json logs and write them to provided logger.
+// The io.Writer will parse json or plaintext logs, redact them, write them to provided
+// logger and pass them to onEntry.
 // Call context.CancelFunc on mongo exit.
-func logProxy(log *zap.Logger, g *errgroup.Group) (io.Writer, context.CancelFunc) {
+func logProxy(log *zap.Logger, g *errgroup.Group, redaction *logRedactor, onEntry func(e Entry)) (io.Writer, context.CancelFunc) {
 	r, w := io.Pipe()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -70,14 +189,28 @@ func logProxy(log *zap.Logger, g *errgroup.Group) (io.Writer, context.CancelFunc
 		log.Info("Log streaming started")
 		defer log.Info("Log streaming ended")
 		for s.Scan() {
-			var e entry
-			if err := json.Unmarshal(s.Bytes(), &e); err != nil {
-				log.Warn("Failed to unmarshal log entry", zap.Error(err))
+			var e Entry
+			if line := s.Bytes(); bytes.HasPrefix(line, []byte("{")) {
+				var err error
+				if e, err = parseJSONEntry(line); err != nil {
+					log.Warn("Failed to unmarshal log entry", zap.Error(err))
+					continue
+				}
+			} else if len(bytes.TrimSpace(line)) > 0 {
+				// Mongo before 4.4 writes plaintext logs.
+				e = parseLegacyEntry(string(line))
+			} else {
 				continue
 			}
+			redaction.apply(&e)
 			e.Log(log)
+			onEntry(e)
+		}
+		// Pipe is closed by flush after process exits.
+		if err := s.Err(); err != io.ErrClosedPipe {
+			return err
 		}
-		return s.Err()
+		return nil
 	})
 
 	return w, cancel
@@ -3,6 +3,10 @@ package booga
 import (
 	"encoding/json"
 	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestLogParsing(t *testing.T) {
@@ -11,9 +15,75 @@ func TestLogParsing(t *testing.T) {
 {"remote":"127.0.0.1:50410","client":"conn3",
 "doc":{"driver":{"name":"mongo-go-driver","version":"v1.4.6"},
 "os":{"type":"linux","architecture":"amd64"},"platform":"go1.16"}}}`)
-	var e entry
+	var e Entry
 	if err := json.Unmarshal(input, &e); err != nil {
 		t.Fatal(err)
 	}
 	t.Logf("%+v", e)
 }
+
+func TestWithLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	log := withLevel(zap.New(core), level)
+
+	log.Debug("hidden")
+	level.SetLevel(zapcore.DebugLevel)
+	log.Debug("shown")
+	level.SetLevel(zapcore.WarnLevel)
+	log.Info("hidden")
+
+	if got := logs.Len(); got != 1 {
+		t.Fatalf("got %d entries, want 1", got)
+	}
+	if msg := logs.All()[0].Message; msg != "shown" {
+		t.Errorf("unexpected entry %q", msg)
+	}
+}
+
+func TestParseLegacyEntry(t *testing.T) {
+	e := parseLegacyEntry("2021-02-27T01:09:52.910+0300 W  NETWORK  [conn3] connection refused")
+	if e.Severity != "W" || e.System != "NETWORK" || e.Context != "conn3" || e.Message != "connection refused" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e.T.Date.IsZero() || e.T.Date.Unix() != 1614377392 {
+		t.Errorf("unexpected time %s", e.T.Date)
+	}
+	if e := parseLegacyEntry("2021-02-27T01:09:52.910+0300 D1 -        [main] debug"); e.Severity != "D1" || e.System != "" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e := parseLegacyEntry("about to fork child process"); e.Severity != "I" || e.Message != "about to fork child process" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+func TestParseFerretDBEntry(t *testing.T) {
+	e, err := parseJSONEntry([]byte(`{"time":"2024-03-01T10:00:00.5Z","level":"WARN","msg":"slow query","name":"sqlite","duration":"1s"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Severity != "W" || e.System != "sqlite" || e.Message != "slow query" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e.T.Date.IsZero() || e.Attributes["duration"] != "1s" || len(e.Attributes) != 1 {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	// Older FerretDB uses zap JSON encoder.
+	e, err = parseJSONEntry([]byte(`{"level":"error","ts":"2023-01-01T00:00:00Z","logger":"listener","msg":"accept failed"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Severity != "E" || e.System != "listener" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	// Mongo entry is kept as is.
+	e, err = parseJSONEntry([]byte(`{"s":"D2","c":"REPL","msg":"m","attr":{"level":1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Severity != "D2" || e.System != "REPL" || e.Attributes["level"] != float64(1) {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
@@ -0,0 +1,185 @@
+package booga
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// LogRecord is log entry of service.
+type LogRecord struct {
+	Service string `json:"service"`
+	Entry
+}
+
+// LogExporter ships log records to external collector.
+type LogExporter interface {
+	Export(ctx context.Context, records []LogRecord) error
+}
+
+const (
+	// exportBatch is maximum count of records in single export.
+	exportBatch = 500
+	// exportInterval is maximum delay of record export.
+	exportInterval = time.Second
+)
+
+func postJSON(ctx context.Context, client *http.Client, url, contentType string, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return xerrors.Errorf("unexpected status %s", res.Status)
+	}
+	return nil
+}
+
+// HTTPExporter posts records as newline-delimited JSON to URL.
+type HTTPExporter struct {
+	URL    string
+	Client *http.Client // http.DefaultClient if nil
+}
+
+func (e HTTPExporter) Export(ctx context.Context, records []LogRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return xerrors.Errorf("encode: %w", err)
+		}
+	}
+	return postJSON(ctx, e.Client, e.URL, "application/x-ndjson", buf.Bytes())
+}
+
+// LokiExporter pushes records to Loki push API, labeling streams by
+// service name.
+type LokiExporter struct {
+	URL    string            // e.g. http://localhost:3100/loki/api/v1/push
+	Labels map[string]string // added to every stream
+	Client *http.Client      // http.DefaultClient if nil
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+func (e LokiExporter) push(records []LogRecord) (lokiPush, error) {
+	streams := map[string]*lokiStream{}
+	for _, r := range records {
+		s, ok := streams[r.Service]
+		if !ok {
+			labels := map[string]string{}
+			for k, v := range e.Labels {
+				labels[k] = v
+			}
+			labels["service"] = r.Service
+			s = &lokiStream{Stream: labels}
+			streams[r.Service] = s
+		}
+		line, err := json.Marshal(r.Entry)
+		if err != nil {
+			return lokiPush{}, xerrors.Errorf("encode: %w", err)
+		}
+		ts := strconv.FormatInt(r.T.Date.UnixNano(), 10)
+		s.Values = append(s.Values, [2]string{ts, string(line)})
+	}
+
+	var p lokiPush
+	for _, s := range streams {
+		p.Streams = append(p.Streams, *s)
+	}
+	sort.Slice(p.Streams, func(i, j int) bool {
+		return p.Streams[i].Stream["service"] < p.Streams[j].Stream["service"]
+	})
+	return p, nil
+}
+
+func (e LokiExporter) Export(ctx context.Context, records []LogRecord) error {
+	p, err := e.push(records)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return xerrors.Errorf("encode: %w", err)
+	}
+	return postJSON(ctx, e.Client, e.URL, "application/json", body)
+}
+
+// queueLog queues log entry of service for export, dropping it if export
+// queue is full.
+func (c *Cluster) queueLog(name string, e Entry) {
+	if c.logQueue == nil {
+		return
+	}
+	select {
+	case c.logQueue <- LogRecord{Service: name, Entry: e}:
+	default:
+	}
+}
+
+// exportLogs ships queued log records to exporter until context is done,
+// flushing remaining records on exit.
+func (c *Cluster) exportLogs(ctx context.Context) {
+	var batch []LogRecord
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := c.logExporter.Export(ctx, batch); err != nil {
+			c.log.Warn("Failed to export logs", zap.Int("records", len(batch)), zap.Error(err))
+		}
+		batch = batch[:0]
+	}
+
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case r := <-c.logQueue:
+					batch = append(batch, r)
+					if len(batch) >= exportBatch {
+						flush(flushCtx)
+					}
+				default:
+					flush(flushCtx)
+					return
+				}
+			}
+		case r := <-c.logQueue:
+			batch = append(batch, r)
+			if len(batch) >= exportBatch {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package booga
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLokiExporterPush(t *testing.T) {
+	e := LokiExporter{Labels: map[string]string{"job": "ci"}}
+	var a, b Entry
+	a.Message = "first"
+	a.T.Date = time.Unix(1, 5)
+	b.Message = "second"
+
+	p, err := e.push([]LogRecord{
+		{Service: "data-0-0", Entry: a},
+		{Service: "cfg", Entry: b},
+		{Service: "data-0-0", Entry: b},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Streams) != 2 {
+		t.Fatalf("got %d streams, want 2", len(p.Streams))
+	}
+	s := p.Streams[1]
+	if s.Stream["service"] != "data-0-0" || s.Stream["job"] != "ci" {
+		t.Errorf("unexpected labels %v", s.Stream)
+	}
+	if len(s.Values) != 2 {
+		t.Fatalf("got %d values, want 2", len(s.Values))
+	}
+	if s.Values[0][0] != "1000000005" {
+		t.Errorf("unexpected timestamp %s", s.Values[0][0])
+	}
+	if _, ok := e.Labels["service"]; ok {
+		t.Error("exporter labels modified")
+	}
+}
@@ -0,0 +1,219 @@
+package booga
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// logHistory is count of latest log entries kept per service.
+const logHistory = 1000
+
+// logRing is bounded buffer of latest log entries.
+type logRing struct {
+	entries []Entry
+	next    int // index of next write when buffer is full
+}
+
+func (r *logRing) add(e Entry) {
+	if len(r.entries) < logHistory {
+		r.entries = append(r.entries, e)
+		return
+	}
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % logHistory
+}
+
+// last returns up to n latest entries, oldest first.
+func (r *logRing) last(n int) []Entry {
+	ordered := append(append([]Entry(nil), r.entries[r.next:]...), r.entries[:r.next]...)
+	if n >= 0 && n < len(ordered) {
+		ordered = ordered[len(ordered)-n:]
+	}
+	return ordered
+}
+
+// logHub broadcasts parsed log entries of services to subscribers and
+// keeps latest entries of every service.
+type logHub struct {
+	mux    sync.Mutex
+	subs   map[chan Entry]string // subscriber -> service name
+	recent map[string]*logRing
+	stats  map[logStatKey]*LogStat
+
+	// watchers are matched under lock, so no entry is missed.
+	watchers map[*logWatcher]struct{}
+}
+
+type logStatKey struct {
+	service   string
+	component string
+}
+
+// LogStat is count of warning and error log entries of service component.
+type LogStat struct {
+	Service   string `json:"service"`
+	Component string `json:"component"`
+	Warnings  int    `json:"warnings"`
+	Errors    int    `json:"errors"` // including fatal
+}
+
+// count updates stats with entry of service.
+func (h *logHub) count(name string, e Entry) {
+	switch e.Severity {
+	case "W", "E", "F":
+	default:
+		return
+	}
+	if h.stats == nil {
+		h.stats = map[logStatKey]*LogStat{}
+	}
+	k := logStatKey{service: name, component: e.System}
+	s, ok := h.stats[k]
+	if !ok {
+		s = &LogStat{Service: name, Component: e.System}
+		h.stats[k] = s
+	}
+	if e.Severity == "W" {
+		s.Warnings++
+	} else {
+		s.Errors++
+	}
+}
+
+// subscribe returns channel of log entries of service. Entries are
+// dropped if subscriber is slow. Returned function unsubscribes.
+func (h *logHub) subscribe(name string) (<-chan Entry, func()) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.subs == nil {
+		h.subs = map[chan Entry]string{}
+	}
+	ch := make(chan Entry, 128)
+	h.subs[ch] = name
+
+	return ch, func() {
+		h.mux.Lock()
+		defer h.mux.Unlock()
+
+		delete(h.subs, ch)
+	}
+}
+
+// logWatcher waits for first entry of service that matches.
+type logWatcher struct {
+	name  string
+	match func(e Entry) bool
+	found chan Entry // buffered, receives single entry
+}
+
+// watch returns channel that receives first published entry of service
+// for which match returns true. Unlike subscribe, no entry is dropped.
+// Match is called with hub locked. Returned function stops watching.
+func (h *logHub) watch(name string, match func(e Entry) bool) (<-chan Entry, func()) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.watchers == nil {
+		h.watchers = map[*logWatcher]struct{}{}
+	}
+	w := &logWatcher{name: name, match: match, found: make(chan Entry, 1)}
+	h.watchers[w] = struct{}{}
+
+	return w.found, func() {
+		h.mux.Lock()
+		defer h.mux.Unlock()
+
+		delete(h.watchers, w)
+	}
+}
+
+// publish sends entry of service to every subscriber.
+func (h *logHub) publish(name string, e Entry) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.recent == nil {
+		h.recent = map[string]*logRing{}
+	}
+	r, ok := h.recent[name]
+	if !ok {
+		r = &logRing{}
+		h.recent[name] = r
+	}
+	r.add(e)
+	h.count(name, e)
+
+	for w := range h.watchers {
+		if w.name == name && w.match(e) {
+			w.found <- e
+			delete(h.watchers, w)
+		}
+	}
+	for ch, sub := range h.subs {
+		if sub != name {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// last returns up to n latest entries of service, oldest first.
+func (h *logHub) last(name string, n int) []Entry {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	r, ok := h.recent[name]
+	if !ok {
+		return nil
+	}
+	return r.last(n)
+}
+
+// LastLogs returns up to n latest log entries of service, oldest first,
+// regardless of logger level. Negative n returns every kept entry, at
+// most logHistory.
+func (c *Cluster) LastLogs(name string, n int) []Entry {
+	return c.logs.last(name, n)
+}
+
+// WaitForLog blocks until service emits log entry for which match returns
+// true and returns that entry. Only entries emitted after call are
+// considered, see LastLogs for earlier entries.
+//
+// Match is called while log entries are dispatched, so it should be fast
+// and must not call methods of cluster.
+func (c *Cluster) WaitForLog(ctx context.Context, name string, match func(e Entry) bool) (Entry, error) {
+	found, stop := c.logs.watch(name, match)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+		return Entry{}, ctx.Err()
+	case e := <-found:
+		return e, nil
+	}
+}
+
+// LogStats returns counts of warnings and errors logged by services since
+// start, sorted by service and component.
+func (c *Cluster) LogStats() []LogStat {
+	c.logs.mux.Lock()
+	defer c.logs.mux.Unlock()
+
+	stats := make([]LogStat, 0, len(c.logs.stats))
+	for _, s := range c.logs.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Service != stats[j].Service {
+			return stats[i].Service < stats[j].Service
+		}
+		return stats[i].Component < stats[j].Component
+	})
+	return stats
+}
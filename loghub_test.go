@@ -0,0 +1,106 @@
+package booga
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLogHubLast(t *testing.T) {
+	var h logHub
+	if got := h.last("data-0-0", 10); len(got) != 0 {
+		t.Fatalf("unexpected entries: %v", got)
+	}
+	for i := 0; i < logHistory+5; i++ {
+		h.publish("data-0-0", Entry{ID: i})
+	}
+	h.publish("cfg", Entry{ID: -1})
+
+	got := h.last("data-0-0", 3)
+	if len(got) != 3 {
+		t.Fatalf("got %d entries", len(got))
+	}
+	for i, e := range got {
+		if want := logHistory + 2 + i; e.ID != want {
+			t.Errorf("entry %d: got id %d, want %d", i, e.ID, want)
+		}
+	}
+	all := h.last("data-0-0", -1)
+	if len(all) != logHistory {
+		t.Fatalf("got %d entries, want %d", len(all), logHistory)
+	}
+	if all[0].ID != 5 {
+		t.Errorf("oldest entry: got id %d, want 5", all[0].ID)
+	}
+}
+
+func TestLogHubStats(t *testing.T) {
+	c := &Cluster{}
+	h := &c.logs
+	for _, e := range []Entry{
+		{Severity: "I", System: "NETWORK"},
+		{Severity: "W", System: "NETWORK"},
+		{Severity: "E", System: "REPL"},
+		{Severity: "F", System: "REPL"},
+	} {
+		h.publish("data-0-0", e)
+	}
+	h.publish("cfg", Entry{Severity: "W", System: "SHARDING"})
+
+	got := c.LogStats()
+	want := []LogStat{
+		{Service: "cfg", Component: "SHARDING", Warnings: 1},
+		{Service: "data-0-0", Component: "NETWORK", Warnings: 1},
+		{Service: "data-0-0", Component: "REPL", Errors: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWaitForLog(t *testing.T) {
+	c := &Cluster{}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	started := make(chan struct{})
+	go func() {
+		// Wait until watcher is registered.
+		for {
+			c.logs.mux.Lock()
+			n := len(c.logs.watchers)
+			c.logs.mux.Unlock()
+			if n > 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		close(started)
+
+		// Burst that overflows buffer of subscription.
+		for i := 0; i < 1000; i++ {
+			c.logs.publish("data-0-0", Entry{ID: i})
+		}
+		c.logs.publish("cfg", Entry{ID: 1001})
+	}()
+
+	e, err := c.WaitForLog(ctx, "data-0-0", func(e Entry) bool { return e.ID == 999 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != 999 {
+		t.Errorf("got entry %d", e.ID)
+	}
+	<-started
+
+	c.logs.mux.Lock()
+	defer c.logs.mux.Unlock()
+	if len(c.logs.watchers) != 0 {
+		t.Error("watcher is not removed")
+	}
+}
@@ -0,0 +1,41 @@
+package booga
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/xerrors"
+)
+
+// SetLogLevel changes minimum level of service logs written to logger.
+// Default is Info. Debug level passes debug entries of services, enabled
+// by SetLogVerbosity.
+func (c *Cluster) SetLogLevel(level zapcore.Level) {
+	c.logLevel.SetLevel(level)
+}
+
+// SetLogVerbosity sets default log verbosity of every running mongod and
+// mongos, from 0 (no debug entries) to 5.
+func (c *Cluster) SetLogVerbosity(ctx context.Context, verbosity int) error {
+	for _, s := range c.Services() {
+		if s.Type == FerretDBServer || s.Type == SidecarServer || s.State != ServiceReady {
+			continue
+		}
+		uri, err := c.serviceURI(s.Name)
+		if err != nil {
+			return err
+		}
+		if err := withClient(ctx, uri, func(client *mongo.Client) error {
+			return client.Database("admin").RunCommand(ctx, bson.D{
+				{Key: "setParameter", Value: 1},
+				{Key: "logComponentVerbosity", Value: bson.M{"verbosity": verbosity}},
+			}).Err()
+		}); err != nil {
+			return xerrors.Errorf("%s: %w", s.Name, err)
+		}
+	}
+
+	return nil
+}
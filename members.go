@@ -0,0 +1,378 @@
+package booga
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// maxMembers is limit of replica ids of shard, so member ports of
+// adjacent shards never overlap.
+const maxMembers = 100
+
+// shardMembers returns replica ids of current members of shard.
+func (c *Cluster) shardMembers(shardID int) []int {
+	c.membersMux.Lock()
+	defer c.membersMux.Unlock()
+
+	if ids, ok := c.members[shardID]; ok {
+		return append([]int(nil), ids...)
+	}
+	ids := make([]int, c.shardReplicas(shardID))
+	for i := range ids {
+		ids[i] = i
+	}
+	return ids
+}
+
+// hasMember reports whether replica id is current member of shard.
+func (c *Cluster) hasMember(shardID, id int) bool {
+	for _, member := range c.shardMembers(shardID) {
+		if member == id {
+			return true
+		}
+	}
+	return false
+}
+
+// updateMembers calls f on replica ids of shard members and saves result.
+func (c *Cluster) updateMembers(shardID int, f func(ids []int) []int) {
+	ids := f(c.shardMembers(shardID))
+
+	c.membersMux.Lock()
+	defer c.membersMux.Unlock()
+
+	if c.members == nil {
+		c.members = map[int][]int{}
+	}
+	c.members[shardID] = ids
+}
+
+// allocateMember returns replica id for new member of shard. Ids of
+// removed members are not reused.
+func (c *Cluster) allocateMember(shardID int) (int, error) {
+	c.membersMux.Lock()
+	defer c.membersMux.Unlock()
+
+	if c.nextMember == nil {
+		c.nextMember = map[int]int{}
+	}
+	id, ok := c.nextMember[shardID]
+	if !ok {
+		id = c.shardReplicas(shardID)
+	}
+	if id >= maxMembers {
+		return 0, xerrors.Errorf("too many members of shard %d", shardID)
+	}
+	c.nextMember[shardID] = id + 1
+
+	return id, nil
+}
+
+// rsMember is replica set member configuration.
+type rsMember struct {
+	ID   int    `bson:"_id"`
+	Host string `bson:"host"`
+	// Rest of member configuration that is kept intact.
+	Rest bson.M `bson:",inline"`
+}
+
+// rsConfig is replica set configuration.
+type rsConfig struct {
+	ID      string     `bson:"_id"`
+	Version int64      `bson:"version"`
+	Members []rsMember `bson:"members"`
+	Rest    bson.M     `bson:",inline"`
+}
+
+// reconfigShard applies f to replica set configuration of shard.
+func (c *Cluster) reconfigShard(ctx context.Context, shardID int, f func(cfg *rsConfig) error) error {
+	return withClient(ctx, c.shardURI(shardID), func(client *mongo.Client) error {
+		admin := client.Database("admin")
+		var reply struct {
+			Config rsConfig `bson:"config"`
+		}
+		if err := admin.RunCommand(ctx, bson.M{"replSetGetConfig": 1}).Decode(&reply); err != nil {
+			return xerrors.Errorf("replSetGetConfig: %w", err)
+		}
+		cfg := reply.Config
+		if err := f(&cfg); err != nil {
+			return err
+		}
+		cfg.Version++
+		// Term is managed by primary.
+		delete(cfg.Rest, "term")
+		if err := admin.RunCommand(ctx, bson.M{"replSetReconfig": cfg}).Err(); err != nil {
+			return xerrors.Errorf("replSetReconfig: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// addMember starts new empty member of shard and adds it to replica set.
+func (c *Cluster) addMember(ctx context.Context, shardID int) (ServiceInfo, error) {
+	if shardID < 0 || shardID >= c.shards {
+		return ServiceInfo{}, xerrors.Errorf("no shard %d", shardID)
+	}
+	if c.ferretDB != "" {
+		return ServiceInfo{}, xerrors.New("no shards with FerretDB")
+	}
+	if c.serve == nil {
+		return ServiceInfo{}, xerrors.New("cluster is not running")
+	}
+	id, err := c.allocateMember(shardID)
+	if err != nil {
+		return ServiceInfo{}, err
+	}
+
+	opt := c.dataServerOptions(shardID, id)
+	opt.OnReady = func(ctx context.Context, client *mongo.Client) error { return nil }
+	c.serve.Go(func() error {
+		return c.runServer(c.serveCtx, opt)
+	})
+	if err := c.services.waitFor(ctx, opt.Name, func(state ServiceState, registered bool) (bool, error) {
+		return registered && state == ServiceReady, nil
+	}); err != nil {
+		return ServiceInfo{}, xerrors.Errorf("wait %s: %w", opt.Name, err)
+	}
+
+	host := localAddr(opt.Port)
+	if err := c.reconfigShard(ctx, shardID, func(cfg *rsConfig) error {
+		memberID := 0
+		for _, m := range cfg.Members {
+			if m.ID >= memberID {
+				memberID = m.ID + 1
+			}
+		}
+		// Member options like tags are kept, but replica set member id
+		// can differ from replica id.
+		rest := c.memberConfig(shardID, id)
+		delete(rest, "_id")
+		delete(rest, "host")
+		cfg.Members = append(cfg.Members, rsMember{ID: memberID, Host: host, Rest: rest})
+		return nil
+	}); err != nil {
+		return ServiceInfo{}, xerrors.Errorf("add %s: %w", host, err)
+	}
+	c.updateMembers(shardID, func(ids []int) []int {
+		return append(ids, id)
+	})
+	c.log.Info("Member added", zap.String("name", opt.Name), zap.String("host", host))
+
+	for _, s := range c.Services() {
+		if s.Name == opt.Name {
+			return s, nil
+		}
+	}
+	return ServiceInfo{}, xerrors.Errorf("no service %s", opt.Name)
+}
+
+// ensureNotPrimary steps down primary of shard if it is member at host
+// and waits for another primary.
+func (c *Cluster) ensureNotPrimary(ctx context.Context, shardID int, host string) error {
+	var primary string
+	if err := c.waitStatus(ctx, shardID, func(s *ReplSetStatus) error {
+		name, ok := s.primary()
+		if !ok {
+			return xerrors.New("no primary")
+		}
+		primary = name
+		return nil
+	}); err != nil {
+		return err
+	}
+	if primary != host {
+		return nil
+	}
+
+	if err := c.stepDown(ctx, shardID); err != nil {
+		return xerrors.Errorf("step down: %w", err)
+	}
+	return c.waitStatus(ctx, shardID, func(s *ReplSetStatus) error {
+		name, ok := s.primary()
+		if !ok || name == host {
+			return xerrors.New("no new primary")
+		}
+		return nil
+	})
+}
+
+// removeMember removes member of shard from replica set, stops it and
+// deletes its data directory.
+func (c *Cluster) removeMember(ctx context.Context, shardID, id int) error {
+	if !c.hasMember(shardID, id) {
+		return xerrors.Errorf("no member %d of shard %d", id, shardID)
+	}
+	if len(c.shardMembers(shardID)) == 1 {
+		return xerrors.Errorf("member %d is the only member of shard %d", id, shardID)
+	}
+	name := c.dataName(shardID, id)
+	host := localAddr(c.dataPort(shardID, id))
+
+	// Primary can't be removed from replica set.
+	if err := c.ensureNotPrimary(ctx, shardID, host); err != nil {
+		return xerrors.Errorf("ensure not primary: %w", err)
+	}
+	if err := c.reconfigShard(ctx, shardID, func(cfg *rsConfig) error {
+		for i, m := range cfg.Members {
+			if m.Host == host {
+				cfg.Members = append(cfg.Members[:i], cfg.Members[i+1:]...)
+				return nil
+			}
+		}
+		return xerrors.Errorf("%s is not member of %s", host, cfg.ID)
+	}); err != nil {
+		return xerrors.Errorf("remove %s: %w", host, err)
+	}
+	c.updateMembers(shardID, func(ids []int) []int {
+		var kept []int
+		for _, member := range ids {
+			if member != id {
+				kept = append(kept, member)
+			}
+		}
+		return kept
+	})
+
+	if err := c.retire(ctx, name); err != nil {
+		return xerrors.Errorf("stop %s: %w", name, err)
+	}
+	if err := os.RemoveAll(filepath.Join(c.dir, name)); err != nil {
+		return xerrors.Errorf("remove data: %w", err)
+	}
+	c.log.Info("Member removed", zap.String("name", name), zap.String("host", host))
+
+	return nil
+}
+
+// ReplaceMember decommissions member of shard replica set by removing it
+// from replica set configuration, stopping it and deleting its data, and
+// adds new empty member on new port instead. Returns new member.
+func (c *Cluster) ReplaceMember(ctx context.Context, shardID, memberID int) (ServiceInfo, error) {
+	if err := c.removeMember(ctx, shardID, memberID); err != nil {
+		return ServiceInfo{}, xerrors.Errorf("remove: %w", err)
+	}
+	s, err := c.addMember(ctx, shardID)
+	if err != nil {
+		return ServiceInfo{}, xerrors.Errorf("add: %w", err)
+	}
+
+	return s, nil
+}
+
+// AddMember starts new empty member of shard and adds it to replica set,
+// so it performs initial sync, see WaitInitialSync.
+func (c *Cluster) AddMember(ctx context.Context, shardID int) (ServiceInfo, error) {
+	return c.addMember(ctx, shardID)
+}
+
+// InitialSync describes initial sync observed by WaitInitialSync.
+type InitialSync struct {
+	Duration       time.Duration // from call until member became secondary
+	FailedAttempts int           // failed initial sync attempts
+}
+
+// memberSyncStatus is subset of replSetGetStatus reply of syncing member.
+type memberSyncStatus struct {
+	MyState           int `bson:"myState"`
+	InitialSyncStatus *struct {
+		FailedInitialSyncAttempts int `bson:"failedInitialSyncAttempts"`
+	} `bson:"initialSyncStatus"`
+}
+
+// WaitInitialSync blocks until member finishes initial sync and becomes
+// secondary.
+func (c *Cluster) WaitInitialSync(ctx context.Context, name string) (InitialSync, error) {
+	uri, err := c.serviceURI(name)
+	if err != nil {
+		return InitialSync{}, err
+	}
+
+	var (
+		start  = time.Now()
+		result InitialSync
+	)
+	if err := withClient(ctx, uri, func(client *mongo.Client) error {
+		b := backoff.NewConstantBackOff(time.Millisecond * 100)
+
+		return backoff.Retry(func() error {
+			var status memberSyncStatus
+			if err := client.Database("admin").
+				RunCommand(ctx, bson.M{"replSetGetStatus": 1, "initialSync": 1}).
+				Decode(&status); err != nil {
+				// Member can be not yet added to replica set.
+				return xerrors.Errorf("replSetGetStatus: %w", err)
+			}
+			if s := status.InitialSyncStatus; s != nil {
+				result.FailedAttempts = s.FailedInitialSyncAttempts
+			}
+			if status.MyState != stateSecondary {
+				return xerrors.Errorf("state %d", status.MyState)
+			}
+			return nil
+		}, backoff.WithContext(b, ctx))
+	}); err != nil {
+		return InitialSync{}, xerrors.Errorf("wait %s: %w", name, err)
+	}
+	result.Duration = time.Since(start)
+	c.log.Info("Initial sync done",
+		zap.String("name", name),
+		zap.Duration("d", result.Duration),
+		zap.Int("failed_attempts", result.FailedAttempts),
+	)
+
+	return result, nil
+}
+
+// memberCommand runs admin command on replica set member by service name.
+func (c *Cluster) memberCommand(ctx context.Context, name string, cmd bson.D) error {
+	uri, err := c.serviceURI(name)
+	if err != nil {
+		return err
+	}
+	return withClient(ctx, uri, func(client *mongo.Client) error {
+		if err := client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+			return xerrors.Errorf("%s: %w", cmd[0].Key, err)
+		}
+		return nil
+	})
+}
+
+// Freeze prevents secondary from seeking election for duration, e.g.
+// during rolling operations. Duration is rounded up to seconds, zero
+// duration unfreezes member.
+func (c *Cluster) Freeze(ctx context.Context, name string, d time.Duration) error {
+	seconds, err := freezeSeconds(d)
+	if err != nil {
+		return err
+	}
+	return c.memberCommand(ctx, name, bson.D{
+		{Key: "replSetFreeze", Value: seconds},
+	})
+}
+
+// freezeSeconds returns replSetFreeze seconds of duration, rounded up, so
+// positive duration never unfreezes member.
+func freezeSeconds(d time.Duration) (int64, error) {
+	if d < 0 {
+		return 0, xerrors.Errorf("negative freeze duration %s", d)
+	}
+	return int64((d + time.Second - 1) / time.Second), nil
+}
+
+// SetMaintenance enters or leaves maintenance mode of secondary, that
+// makes it RECOVERING, so it serves no reads. Calls are counted by
+// server, so every enter should be paired with leave.
+func (c *Cluster) SetMaintenance(ctx context.Context, name string, on bool) error {
+	return c.memberCommand(ctx, name, bson.D{
+		{Key: "replSetMaintenance", Value: on},
+	})
+}
@@ -0,0 +1,27 @@
+package booga
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreezeSeconds(t *testing.T) {
+	for d, expected := range map[time.Duration]int64{
+		0:                              0,
+		time.Millisecond * 500:         1,
+		time.Second:                    1,
+		time.Second + time.Millisecond: 2,
+		time.Minute:                    60,
+	} {
+		got, err := freezeSeconds(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("%s: got %d, want %d", d, got, expected)
+		}
+	}
+	if _, err := freezeSeconds(-time.Second); err == nil {
+		t.Error("expected error for negative duration")
+	}
+}
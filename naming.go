@@ -0,0 +1,127 @@
+package booga
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Naming configures names of replica sets and services.
+//
+// Shard names are equal to names of shard replica sets.
+type Naming struct {
+	// Prefix is prepended to every name, e.g. "orders-" to distinguish
+	// multiple clusters.
+	Prefix string
+
+	// ConfigReplicaSet is name of config server replica set,
+	// "rsConfig" by default.
+	ConfigReplicaSet string
+	// ShardReplicaSet is format of shard replica set name with shard id
+	// argument, "rsData%d" by default.
+	ShardReplicaSet string
+
+	// ConfigServer is service name of config server, "cfg" by default.
+	ConfigServer string
+	// DataServer is format of data server service name with shard id and
+	// replica id arguments, "data-%d-%d" by default.
+	DataServer string
+	// RoutingServer is service name of router, "routing" by default.
+	RoutingServer string
+}
+
+func (n Naming) withDefaults() Naming {
+	if n.ConfigReplicaSet == "" {
+		n.ConfigReplicaSet = "rsConfig"
+	}
+	if n.ShardReplicaSet == "" {
+		n.ShardReplicaSet = "rsData%d"
+	}
+	if n.ConfigServer == "" {
+		n.ConfigServer = "cfg"
+	}
+	if n.DataServer == "" {
+		n.DataServer = "data-%d-%d"
+	}
+	if n.RoutingServer == "" {
+		n.RoutingServer = "routing"
+	}
+	return n
+}
+
+// formatVerb matches verb of format string, e.g. "%d" or "%02d".
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+// intVerbs reports whether format has exactly n verbs and every verb
+// formats integer.
+func intVerbs(format string, n int) bool {
+	verbs := formatVerb.FindAllString(strings.Replace(format, "%%", "", -1), -1)
+	if len(verbs) != n || strings.Count(strings.Replace(format, "%%", "", -1), "%") != n {
+		return false
+	}
+	for _, v := range verbs {
+		if !strings.HasSuffix(v, "d") {
+			return false
+		}
+	}
+	return true
+}
+
+// validate returns problems of naming with defaults applied. Service names
+// are directory names, so path separators are rejected.
+func (n Naming) validate() []string {
+	var problems []string
+	if !intVerbs(n.ShardReplicaSet, 1) {
+		problems = append(problems, fmt.Sprintf("Naming.ShardReplicaSet is %q: set format with single shard id verb, e.g. \"rsData%%d\"", n.ShardReplicaSet))
+	}
+	if !intVerbs(n.DataServer, 2) {
+		problems = append(problems, fmt.Sprintf("Naming.DataServer is %q: set format with shard id and replica id verbs, e.g. \"data-%%d-%%d\"", n.DataServer))
+	}
+	for _, f := range []struct{ Name, Value string }{
+		{"Prefix", n.Prefix},
+		{"ConfigReplicaSet", n.ConfigReplicaSet},
+		{"ShardReplicaSet", n.ShardReplicaSet},
+		{"ConfigServer", n.ConfigServer},
+		{"DataServer", n.DataServer},
+		{"RoutingServer", n.RoutingServer},
+	} {
+		if strings.ContainsAny(f.Value, `/\`) {
+			problems = append(problems, fmt.Sprintf("Naming.%s is %q: remove path separators, names are used as directory names", f.Name, f.Value))
+		}
+	}
+	return problems
+}
+
+// configReplicaSet returns replica set name of config server.
+func (c *Cluster) configReplicaSet() string {
+	return c.naming.Prefix + c.naming.ConfigReplicaSet
+}
+
+// shardReplicaSet returns replica set name of shard.
+func (c *Cluster) shardReplicaSet(shardID int) string {
+	return c.naming.Prefix + fmt.Sprintf(c.naming.ShardReplicaSet, shardID)
+}
+
+// configName returns service name of config server.
+func (c *Cluster) configName() string {
+	return c.naming.Prefix + c.naming.ConfigServer
+}
+
+// configMemberName returns service name of config server replica set
+// member. First member is named as single config server.
+func (c *Cluster) configMemberName(id int) string {
+	if id == 0 {
+		return c.configName()
+	}
+	return fmt.Sprintf("%s-%d", c.configName(), id)
+}
+
+// dataName returns service name of shard replica set member.
+func (c *Cluster) dataName(shardID, id int) string {
+	return c.naming.Prefix + fmt.Sprintf(c.naming.DataServer, shardID, id)
+}
+
+// routingName returns service name of router.
+func (c *Cluster) routingName() string {
+	return c.naming.Prefix + c.naming.RoutingServer
+}
@@ -0,0 +1,55 @@
+package booga
+
+import "testing"
+
+func TestNaming(t *testing.T) {
+	c := New(Config{})
+	for _, tt := range []struct{ Got, Expected string }{
+		{Got: c.configReplicaSet(), Expected: "rsConfig"},
+		{Got: c.shardReplicaSet(1), Expected: "rsData1"},
+		{Got: c.configName(), Expected: "cfg"},
+		{Got: c.configMemberName(0), Expected: "cfg"},
+		{Got: c.configMemberName(2), Expected: "cfg-2"},
+		{Got: c.dataName(1, 2), Expected: "data-1-2"},
+		{Got: c.routingName(), Expected: "routing"},
+	} {
+		if tt.Got != tt.Expected {
+			t.Errorf("default: got %q, expected %q", tt.Got, tt.Expected)
+		}
+	}
+
+	c = New(Config{Naming: Naming{
+		Prefix:          "orders-",
+		ShardReplicaSet: "shard%02d",
+		DataServer:      "shard%02d-node%d",
+	}})
+	for _, tt := range []struct{ Got, Expected string }{
+		{Got: c.configReplicaSet(), Expected: "orders-rsConfig"},
+		{Got: c.shardReplicaSet(1), Expected: "orders-shard01"},
+		{Got: c.dataName(1, 2), Expected: "orders-shard01-node2"},
+		{Got: c.routingName(), Expected: "orders-routing"},
+	} {
+		if tt.Got != tt.Expected {
+			t.Errorf("custom: got %q, expected %q", tt.Got, tt.Expected)
+		}
+	}
+}
+
+func TestNamingValidate(t *testing.T) {
+	for _, tt := range []struct {
+		Naming   Naming
+		Problems int
+	}{
+		{Naming: Naming{}, Problems: 0},
+		{Naming: Naming{ShardReplicaSet: "shard%02d", DataServer: "shard%02d-node%d"}, Problems: 0},
+		{Naming: Naming{ShardReplicaSet: "shard", DataServer: "data-%d"}, Problems: 2},
+		{Naming: Naming{ShardReplicaSet: "rs%s", DataServer: "data-%d-%d-%d"}, Problems: 2},
+		{Naming: Naming{ShardReplicaSet: "rs%d%%", DataServer: "data-%d-%v"}, Problems: 1},
+		{Naming: Naming{Prefix: "a/b-", RoutingServer: `r\\s`}, Problems: 2},
+		{Naming: Naming{DataServer: "%d/%d"}, Problems: 1},
+	} {
+		if got := tt.Naming.withDefaults().validate(); len(got) != tt.Problems {
+			t.Errorf("%+v: got %d problems %v, expected %d", tt.Naming, len(got), got, tt.Problems)
+		}
+	}
+}
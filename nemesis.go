@@ -0,0 +1,231 @@
+package booga
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/multierr"
+	"golang.org/x/xerrors"
+)
+
+// Nemesis injects fault to cluster and recovers it.
+//
+// Recover is called after successful Inject, so implementations can keep
+// state of injected fault between calls.
+type Nemesis interface {
+	Inject(ctx context.Context, c *Cluster) error
+	Recover(ctx context.Context, c *Cluster) error
+}
+
+// faultNemesis is Nemesis of built-in fault.
+type faultNemesis struct {
+	fault   Fault
+	target  ServiceInfo
+	recover func(ctx context.Context) error
+}
+
+func (n *faultNemesis) Inject(ctx context.Context, c *Cluster) error {
+	f, err := c.inject(ctx, n.fault, n.target)
+	if err != nil {
+		return err
+	}
+	n.recover = f
+	return nil
+}
+
+func (n *faultNemesis) Recover(ctx context.Context, c *Cluster) error {
+	return n.recover(ctx)
+}
+
+// shardPrimary returns service of shard primary.
+func (c *Cluster) shardPrimary(ctx context.Context, shardID int) (ServiceInfo, error) {
+	var addr string
+	if err := withClient(ctx, c.shardURI(shardID), func(client *mongo.Client) error {
+		status, err := replSetGetStatus(ctx, client)
+		if err != nil {
+			return err
+		}
+		for _, m := range status.Members {
+			if m.State == statePrimary {
+				addr = m.Name
+			}
+		}
+		return nil
+	}); err != nil {
+		return ServiceInfo{}, err
+	}
+	if addr == "" {
+		return ServiceInfo{}, xerrors.Errorf("no primary of %s", c.shardReplicaSet(shardID))
+	}
+
+	for _, s := range c.Services() {
+		if s.Addr == addr {
+			return s, nil
+		}
+	}
+	return ServiceInfo{}, xerrors.Errorf("no service with address %s", addr)
+}
+
+// PrimaryKiller kills current primary of shard and starts it again on
+// recovery.
+type PrimaryKiller struct {
+	ShardID int
+
+	killed string
+}
+
+func (n *PrimaryKiller) Inject(ctx context.Context, c *Cluster) error {
+	s, err := c.shardPrimary(ctx, n.ShardID)
+	if err != nil {
+		return xerrors.Errorf("primary: %w", err)
+	}
+	n.killed = s.Name
+
+	// Primary is considered failed only after process exits.
+	_, err = c.KillWait(ctx, s.Name)
+	return err
+}
+
+func (n *PrimaryKiller) Recover(ctx context.Context, c *Cluster) error {
+	if err := c.waitServiceState(ctx, n.killed, ServiceStopped); err != nil {
+		return err
+	}
+	return c.Restart(n.killed)
+}
+
+// Partitioner drops network traffic of services until recovery, see
+// Config.EnablePartitions.
+type Partitioner struct {
+	Targets []string // service names
+	// Inbound drops only traffic sent to targets, so targets can reach
+	// other services, but not vice versa.
+	Inbound bool
+
+	heal []func() error
+}
+
+func (n *Partitioner) Inject(ctx context.Context, c *Cluster) error {
+	services := map[string]ServiceInfo{}
+	for _, s := range c.Services() {
+		services[s.Name] = s
+	}
+	for _, name := range n.Targets {
+		s, ok := services[name]
+		if !ok {
+			err := xerrors.Errorf("no service %s", name)
+			return multierr.Append(err, n.Recover(ctx, c))
+		}
+		heal, err := c.isolate(s, n.Inbound)
+		if err != nil {
+			// Recover is not called after failed Inject.
+			err = xerrors.Errorf("isolate %s: %w", name, err)
+			return multierr.Append(err, n.Recover(ctx, c))
+		}
+		n.heal = append(n.heal, heal)
+	}
+	return nil
+}
+
+func (n *Partitioner) Recover(ctx context.Context, c *Cluster) error {
+	var errs error
+	for _, heal := range n.heal {
+		errs = multierr.Append(errs, heal())
+	}
+	n.heal = nil
+	if errs != nil {
+		return xerrors.Errorf("heal: %w", errs)
+	}
+	return nil
+}
+
+// Slowdown slows service down by repeatedly pausing its process for
+// fraction of every period until recovery. Slowdown can't be injected
+// again before Recover.
+type Slowdown struct {
+	Target string        // service name
+	Period time.Duration // 100ms by default
+	Paused float64       // fraction of period, 0.5 by default
+
+	mux    sync.Mutex
+	cancel context.CancelFunc // nil if not injected
+	wg     sync.WaitGroup
+}
+
+func (n *Slowdown) Inject(ctx context.Context, c *Cluster) error {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	if n.cancel != nil {
+		return xerrors.Errorf("slowdown of %s is already injected", n.Target)
+	}
+
+	p, err := c.Process(n.Target)
+	if err != nil {
+		return err
+	}
+	period, paused := n.Period, n.Paused
+	if period <= 0 {
+		period = time.Millisecond * 100
+	}
+	if paused <= 0 || paused >= 1 {
+		paused = 0.5
+	}
+	pause := time.Duration(float64(period) * paused)
+	run := period - pause
+
+	if err := pauseProcess(p); err != nil {
+		return xerrors.Errorf("pause: %w", err)
+	}
+
+	// Slowdown lasts until Recover, not until end of ctx.
+	slowCtx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		defer func() { _ = resumeProcess(p) }()
+		for {
+			if !sleep(slowCtx, pause) {
+				return
+			}
+			if err := resumeProcess(p); err != nil {
+				return
+			}
+			if !sleep(slowCtx, run) {
+				return
+			}
+			if err := pauseProcess(p); err != nil {
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (n *Slowdown) Recover(ctx context.Context, c *Cluster) error {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	if n.cancel == nil {
+		return nil
+	}
+
+	n.cancel()
+	n.cancel = nil
+	n.wg.Wait()
+	return nil
+}
+
+// StepDown steps down current primary of shard. Recover is no-op.
+type StepDown struct {
+	ShardID int
+}
+
+func (n *StepDown) Inject(ctx context.Context, c *Cluster) error {
+	return c.stepDown(ctx, n.ShardID)
+}
+
+func (n *StepDown) Recover(ctx context.Context, c *Cluster) error {
+	return nil
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+// +build !windows
+
+package booga
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSlowdownReinject(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	c := New(Config{Log: zap.NewNop()})
+	if err := c.services.add(&service{info: ServiceInfo{Name: "app", State: ServiceReady}, process: cmd.Process}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	n := &Slowdown{Target: "app", Period: time.Millisecond * 10}
+	if err := n.Inject(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Inject(ctx, c); err == nil {
+		t.Error("expected error of second inject")
+	}
+	if err := n.Recover(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Recover(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Slowdown can be injected again after recovery.
+	if err := n.Inject(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Recover(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+}
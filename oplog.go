@@ -0,0 +1,111 @@
+package booga
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// lastOplogTimestamp returns timestamp of latest oplog entry.
+func lastOplogTimestamp(ctx context.Context, oplog *mongo.Collection) (primitive.Timestamp, error) {
+	var last struct {
+		TS primitive.Timestamp `bson:"ts"`
+	}
+	if err := oplog.FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.M{"$natural": -1}),
+	).Decode(&last); err != nil {
+		return primitive.Timestamp{}, xerrors.Errorf("find: %w", err)
+	}
+
+	return last.TS, nil
+}
+
+// TailOplog opens tailable cursor on oplog of shard primary and sends
+// entries that are written after call until context cancellation.
+//
+// Returned channel is closed when tailing stops.
+func (c *Cluster) TailOplog(ctx context.Context, shardID int) (<-chan bson.Raw, error) {
+	if shardID < 0 || shardID >= c.shards {
+		return nil, xerrors.Errorf("no shard %d", shardID)
+	}
+	entries, _, err := c.tailOplog(ctx, shardID, nil)
+	return entries, err
+}
+
+// tailOplog sends oplog entries of shard written after given timestamp,
+// or after call if nil, and returns timestamp that entries follow. Returns
+// error if entries after timestamp are already removed from oplog.
+func (c *Cluster) tailOplog(ctx context.Context, shardID int, after *primitive.Timestamp) (<-chan bson.Raw, primitive.Timestamp, error) {
+	client, err := connect(ctx, c.shardURI(shardID))
+	if err != nil {
+		return nil, primitive.Timestamp{}, err
+	}
+
+	oplog := client.Database("local").Collection("oplog.rs")
+	var from primitive.Timestamp
+	cursor, err := func() (*mongo.Cursor, error) {
+		if after == nil {
+			ts, err := lastOplogTimestamp(ctx, oplog)
+			if err != nil {
+				return nil, xerrors.Errorf("last entry: %w", err)
+			}
+			from = ts
+		} else {
+			from = *after
+			// Oldest entry is removed first, so entry at timestamp is
+			// present only if no later entry was removed.
+			n, err := oplog.CountDocuments(ctx, bson.M{"ts": bson.M{"$lte": from}}, options.Count().SetLimit(1))
+			if err != nil {
+				return nil, xerrors.Errorf("count: %w", err)
+			}
+			if n == 0 {
+				return nil, xerrors.Errorf("oplog entries after %v are removed", from)
+			}
+		}
+		cursor, err := oplog.Find(ctx, bson.M{"ts": bson.M{"$gt": from}},
+			options.Find().SetCursorType(options.TailableAwait),
+		)
+		if err != nil {
+			return nil, xerrors.Errorf("find: %w", err)
+		}
+		return cursor, nil
+	}()
+	if err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, primitive.Timestamp{}, err
+	}
+
+	entries := make(chan bson.Raw)
+	go func() {
+		defer close(entries)
+		defer func() {
+			_ = client.Disconnect(context.Background())
+		}()
+		defer func() {
+			_ = cursor.Close(context.Background())
+		}()
+
+		for cursor.Next(ctx) {
+			// Current document is reused by cursor.
+			entry := append(bson.Raw(nil), cursor.Current...)
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := cursor.Err(); err != nil && ctx.Err() == nil {
+			c.log.Warn("Oplog tailing failed",
+				zap.String("rs", c.shardReplicaSet(shardID)),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	return entries, from, nil
+}
@@ -0,0 +1,77 @@
+package booga
+
+import "go.uber.org/zap"
+
+// Option configures cluster created by NewCluster.
+type Option func(opt *Config)
+
+// NewCluster returns new cluster configured by options on top of Minimal
+// preset, alternative to New.
+func NewCluster(opts ...Option) *Cluster {
+	opt := Minimal()
+	for _, o := range opts {
+		o(&opt)
+	}
+	return New(opt)
+}
+
+// WithShards sets count of shards.
+func WithShards(n int) Option {
+	return func(opt *Config) { opt.Shards = n }
+}
+
+// WithReplicas sets count of members of every shard replica set.
+func WithReplicas(n int) Option {
+	return func(opt *Config) { opt.Replicas = n }
+}
+
+// WithConfigReplicas sets count of members of config server replica set.
+func WithConfigReplicas(n int) Option {
+	return func(opt *Config) { opt.ConfigReplicas = n }
+}
+
+// WithAuth enables access control with default credentials, see
+// Config.Auth.
+func WithAuth() Option {
+	return func(opt *Config) { opt.Auth = &Credentials{} }
+}
+
+// WithCredentials enables access control with given credentials.
+func WithCredentials(username, password string) Option {
+	return func(opt *Config) { opt.Auth = &Credentials{Username: username, Password: password} }
+}
+
+// WithVersion requires binaries of given version or version prefix,
+// e.g. "7.0", see Config.Version.
+func WithVersion(version string) Option {
+	return func(opt *Config) { opt.Version = version }
+}
+
+// WithBinaries sets mongod and mongos binary paths.
+func WithBinaries(mongod, mongos string) Option {
+	return func(opt *Config) {
+		opt.Mongod = mongod
+		opt.Mongos = mongos
+	}
+}
+
+// WithLogger sets logger of cluster.
+func WithLogger(log *zap.Logger) Option {
+	return func(opt *Config) { opt.Log = log }
+}
+
+// WithDir sets base directory of cluster.
+func WithDir(dir string) Option {
+	return func(opt *Config) { opt.Dir = dir }
+}
+
+// WithDB sets name of sharded database.
+func WithDB(db string) Option {
+	return func(opt *Config) { opt.DB = db }
+}
+
+// WithPreset replaces configuration with preset, e.g. ProductionLike, so
+// following options adjust it.
+func WithPreset(preset func() Config) Option {
+	return func(opt *Config) { *opt = preset() }
+}
@@ -0,0 +1,61 @@
+package booga
+
+import "testing"
+
+func TestNewCluster(t *testing.T) {
+	c := NewCluster(
+		WithPreset(ProductionLike),
+		WithShards(2),
+		WithReplicas(1),
+		WithVersion("7.0"),
+	)
+	if c.shards != 2 || c.shardReplicas(0) != 1 {
+		t.Errorf("unexpected topology: %d shards of %d", c.shards, c.shardReplicas(0))
+	}
+	if !c.majorityConcerns || !c.inspectBinaries || c.version != "7.0" {
+		t.Error("options are not applied")
+	}
+	if c.configReplicas != 3 || c.auth == nil || c.auth.Username == "" {
+		t.Error("preset is not applied")
+	}
+
+	c = NewCluster(WithAuth(), WithConfigReplicas(3))
+	if c.auth == nil || *c.auth != (Credentials{Username: "booga", Password: "booga"}) || c.configReplicas != 3 {
+		t.Errorf("unexpected auth %+v of %d config servers", c.auth, c.configReplicas)
+	}
+	if c := NewCluster(WithCredentials("app", "secret")); c.auth == nil || c.auth.Username != "app" {
+		t.Errorf("unexpected credentials %+v", c.auth)
+	}
+	if c := NewCluster(); c.auth != nil {
+		t.Error("auth is enabled by default")
+	}
+
+	if db := NewCluster(WithDB("orders")).db; db != "orders" {
+		t.Errorf("unexpected db %q", db)
+	}
+	if db := New(Config{}).db; db != defaultDB {
+		t.Errorf("unexpected default db %q", db)
+	}
+	if db := New(Minimal()).db; db != defaultDB {
+		t.Errorf("unexpected db %q of preset", db)
+	}
+}
+
+func TestVersionMatches(t *testing.T) {
+	for _, tt := range []struct {
+		Line     string
+		Expected string
+		Result   bool
+	}{
+		{Line: "db version v7.0.2", Expected: "7.0", Result: true},
+		{Line: "mongos version v7.0.2", Expected: "7", Result: true},
+		{Line: "db version v7.0.2", Expected: "7.0.2", Result: true},
+		{Line: "db version v7.0.12", Expected: "7.0.1", Result: false},
+		{Line: "db version v6.0.5", Expected: "7.0", Result: false},
+		{Line: "garbage", Expected: "7.0", Result: false},
+	} {
+		if got := versionMatches(binaryVersion(tt.Line), tt.Expected); got != tt.Result {
+			t.Errorf("%q ~ %s: got %v", tt.Line, tt.Expected, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package booga
+
+import (
+	"context"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+func setClusterParameter(ctx context.Context, client *mongo.Client, name string, value interface{}) error {
+	info, err := getBuildInfo(ctx, client)
+	if err != nil {
+		return xerrors.Errorf("version: %w", err)
+	}
+	if !info.AtLeast(6, 0) {
+		return xerrors.Errorf("setClusterParameter is not supported by %s", info.Version)
+	}
+	if err := client.Database("admin").
+		RunCommand(ctx, bson.M{"setClusterParameter": bson.M{name: value}}).
+		Err(); err != nil {
+		return xerrors.Errorf("setClusterParameter %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// SetClusterParameter sets cluster-wide parameter via routing server,
+// e.g. "changeStreamOptions" to bson.M{"preAndPostImages": ...}.
+//
+// Requires MongoDB 6.0 or newer.
+func (c *Cluster) SetClusterParameter(ctx context.Context, name string, value interface{}) error {
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		return setClusterParameter(ctx, client, name, value)
+	})
+}
+
+// setupClusterParameters sets Config.ClusterParameters.
+func (c *Cluster) setupClusterParameters(ctx context.Context, client *mongo.Client) error {
+	names := make([]string, 0, len(c.clusterParameters))
+	for name := range c.clusterParameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := setClusterParameter(ctx, client, name, c.clusterParameters[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
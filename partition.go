@@ -0,0 +1,50 @@
+package booga
+
+import (
+	"strconv"
+
+	"golang.org/x/xerrors"
+)
+
+// iptablesPartitionRules returns iptables rule specifications that drop
+// loopback traffic of service listening on port and running in cgroup:
+// connections to port and every packet sent by service, including
+// connections that service opens to other services.
+func iptablesPartitionRules(port int, cgroup string) [][]string {
+	return [][]string{
+		{"INPUT", "-i", "lo", "-p", "tcp", "--dport", strconv.Itoa(port), "-j", "DROP"},
+		{"OUTPUT", "-o", "lo", "-m", "cgroup", "--path", cgroup, "-j", "DROP"},
+	}
+}
+
+// iptablesInboundRules returns iptables rule specifications that drop
+// loopback traffic sent to port, so connections to port fail while
+// connections from listening service to other ports work.
+func iptablesInboundRules(port int) [][]string {
+	return iptablesPartitionRules(port, "")[:1]
+}
+
+// isolate drops network traffic of service until returned heal is
+// called. If inbound, only traffic sent to service is dropped.
+func (c *Cluster) isolate(s ServiceInfo, inbound bool) (heal func() error, err error) {
+	port, err := servicePort(s)
+	if err != nil {
+		return nil, xerrors.Errorf("port: %w", err)
+	}
+
+	rules := iptablesInboundRules(port)
+	if !inbound {
+		if c.cgroups == nil {
+			return nil, xerrors.New("partitions are not enabled, see Config.EnablePartitions")
+		}
+		if s.Type == SidecarServer {
+			return nil, xerrors.Errorf("sidecar %s can't be partitioned", s.Name)
+		}
+		rules = iptablesPartitionRules(port, c.cgroups.path(s.Name))
+	}
+	if err := applyRules(rules); err != nil {
+		return nil, err
+	}
+
+	return func() error { return removeRules(rules) }, nil
+}
@@ -0,0 +1,134 @@
+package booga
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/multierr"
+	"golang.org/x/xerrors"
+)
+
+func iptables(action string, rule []string) error {
+	args := append([]string{action}, rule...)
+	if out, err := exec.Command("iptables", args...).CombinedOutput(); err != nil {
+		return xerrors.Errorf("iptables %v: %w: %s", args, err, out)
+	}
+	return nil
+}
+
+// applyRules appends iptables rules, deleting already appended ones if
+// any rule fails, so no rule is left behind.
+func applyRules(rules [][]string) error {
+	for i, rule := range rules {
+		if err := iptables("-A", rule); err != nil {
+			for _, applied := range rules[:i] {
+				err = multierr.Append(err, iptables("-D", applied))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// removeRules deletes iptables rules added by applyRules.
+func removeRules(rules [][]string) error {
+	var errs error
+	for _, rule := range rules {
+		errs = multierr.Append(errs, iptables("-D", rule))
+	}
+	return errs
+}
+
+// parseCgroupMount returns mount point of cgroup v2 hierarchy from
+// /proc/self/mountinfo.
+func parseCgroupMount(r io.Reader) (string, error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		// Optional fields are terminated by "-", followed by filesystem
+		// type, see proc(5).
+		fields := strings.Fields(s.Text())
+		for i, f := range fields {
+			if f == "-" && i+1 < len(fields) && i > 4 {
+				if fields[i+1] == "cgroup2" {
+					return fields[4], nil
+				}
+				break
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", xerrors.New("cgroup v2 is not mounted")
+}
+
+// serviceCgroups places every service process into own cgroup v2, so
+// iptables can match all traffic of service by cgroup path, including
+// connections that service opens from ephemeral ports.
+type serviceCgroups struct {
+	root string // mount point of cgroup v2 hierarchy
+	dir  string // directory of cluster relative to root
+}
+
+func newServiceCgroups() (*serviceCgroups, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, xerrors.Errorf("open: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	root, err := parseCgroupMount(f)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath("iptables"); err != nil {
+		return nil, xerrors.Errorf("iptables: %w", err)
+	}
+	g := &serviceCgroups{
+		root: root,
+		dir:  "booga-" + strconv.Itoa(os.Getpid()),
+	}
+	if err := os.MkdirAll(filepath.Join(root, g.dir), 0755); err != nil {
+		return nil, xerrors.Errorf("mkdir: %w", err)
+	}
+	return g, nil
+}
+
+// path returns cgroup path of service relative to hierarchy root, as
+// matched by iptables.
+func (g *serviceCgroups) path(name string) string {
+	return g.dir + "/" + name
+}
+
+// command returns command that moves itself to cgroup of service and
+// then execs binary, so every socket of process belongs to cgroup.
+func (g *serviceCgroups) command(name, binary string, args []string) (string, []string, error) {
+	dir := filepath.Join(g.root, g.path(name))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, xerrors.Errorf("mkdir: %w", err)
+	}
+	wrapped := []string{"-c", `echo $$ > "$0" && exec "$@"`, filepath.Join(dir, "cgroup.procs"), binary}
+	return "sh", append(wrapped, args...), nil
+}
+
+// close removes cgroups of stopped services.
+func (g *serviceCgroups) close() error {
+	dir := filepath.Join(g.root, g.dir)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return xerrors.Errorf("read dir: %w", err)
+	}
+	var errs error
+	for _, e := range entries {
+		if e.IsDir() {
+			errs = multierr.Append(errs, os.Remove(filepath.Join(dir, e.Name())))
+		}
+	}
+	return multierr.Append(errs, os.Remove(dir))
+}
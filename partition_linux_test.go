@@ -0,0 +1,96 @@
+package booga
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIptablesInboundRules(t *testing.T) {
+	expected := [][]string{
+		{"INPUT", "-i", "lo", "-p", "tcp", "--dport", "29000", "-j", "DROP"},
+	}
+	if got := iptablesInboundRules(29000); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected rules %v", got)
+	}
+}
+
+func TestParseCgroupMount(t *testing.T) {
+	const mountinfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+25 22 0:22 / /sys/fs/cgroup ro,nosuid shared:9 - tmpfs tmpfs ro,mode=755
+26 25 0:23 / /sys/fs/cgroup/unified rw,nosuid shared:10 - cgroup2 cgroup2 rw
+27 25 0:24 / /sys/fs/cgroup/cpu rw,nosuid shared:11 - cgroup cgroup rw,cpu
+`
+	got, err := parseCgroupMount(strings.NewReader(mountinfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "/sys/fs/cgroup/unified" {
+		t.Errorf("unexpected mount %q", got)
+	}
+	if _, err := parseCgroupMount(strings.NewReader(mountinfo[:strings.Index(mountinfo, "26 ")])); err == nil {
+		t.Error("expected error without cgroup2")
+	}
+}
+
+func TestApplyRulesRollback(t *testing.T) {
+	// Fake iptables logs calls and fails to append OUTPUT rule.
+	dir, err := ioutil.TempDir("", "booga-iptables")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	log := filepath.Join(dir, "log")
+	script := "#!/bin/sh\necho \"$*\" >> " + log + "\n" +
+		"case \"$*\" in \"-A OUTPUT\"*) exit 1;; esac\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "iptables"), []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	defer func() { _ = os.Setenv("PATH", path) }()
+	if err := os.Setenv("PATH", dir+string(os.PathListSeparator)+path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyRules(iptablesPartitionRules(29000, "booga-1/data-0-0")); err == nil {
+		t.Fatal("expected error")
+	}
+	data, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "-A INPUT -i lo -p tcp --dport 29000 -j DROP\n" +
+		"-A OUTPUT -o lo -m cgroup --path booga-1/data-0-0 -j DROP\n" +
+		"-D INPUT -i lo -p tcp --dport 29000 -j DROP\n"
+	if string(data) != expected {
+		t.Errorf("unexpected calls:\n%s", data)
+	}
+}
+
+func TestServiceCgroupsCommand(t *testing.T) {
+	root, err := ioutil.TempDir("", "booga-cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(root) }()
+
+	g := &serviceCgroups{root: root, dir: "booga-1"}
+	binary, args, err := g.command("data-0-0", "mongod", []string{"--port", "29000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	procs := filepath.Join(root, "booga-1", "data-0-0", "cgroup.procs")
+	expected := []string{"-c", `echo $$ > "$0" && exec "$@"`, procs, "mongod", "--port", "29000"}
+	if binary != "sh" || !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected command %s %v", binary, args)
+	}
+	if got := g.path("data-0-0"); got != "booga-1/data-0-0" {
+		t.Errorf("unexpected path %q", got)
+	}
+	if err := g.close(); err != nil {
+		t.Fatal(err)
+	}
+}
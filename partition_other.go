@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+package booga
+
+import "golang.org/x/xerrors"
+
+func applyRules(rules [][]string) error {
+	return xerrors.New("network partitions are supported only on linux")
+}
+
+func removeRules(rules [][]string) error {
+	return xerrors.New("network partitions are supported only on linux")
+}
+
+type serviceCgroups struct{}
+
+func newServiceCgroups() (*serviceCgroups, error) {
+	return nil, xerrors.New("network partitions are supported only on linux")
+}
+
+func (g *serviceCgroups) path(name string) string {
+	return name
+}
+
+func (g *serviceCgroups) command(name, binary string, args []string) (string, []string, error) {
+	return binary, args, nil
+}
+
+func (g *serviceCgroups) close() error {
+	return nil
+}
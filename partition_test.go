@@ -0,0 +1,82 @@
+package booga
+
+import (
+	"strconv"
+	"testing"
+)
+
+// packet is loopback TCP packet as seen by iptables.
+type packet struct {
+	chain  string // OUTPUT when sent, INPUT when received
+	sport  int
+	dport  int
+	cgroup string // cgroup of sending socket, only for OUTPUT
+}
+
+// dropped reports whether packet matches any of DROP rules. Only options
+// used by partition rules are supported.
+func dropped(rules [][]string, p packet) bool {
+	for _, rule := range rules {
+		match := rule[0] == p.chain
+		for i := 1; i+1 < len(rule); i++ {
+			switch rule[i] {
+			case "--dport":
+				match = match && rule[i+1] == strconv.Itoa(p.dport)
+			case "--sport":
+				match = match && rule[i+1] == strconv.Itoa(p.sport)
+			case "--path":
+				match = match && rule[i+1] == p.cgroup
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// connects reports whether connection from client in cgroup to server
+// listening on port succeeds: every packet of both directions passes
+// OUTPUT and then INPUT chain of loopback.
+func connects(rules [][]string, client string, server string, port int) bool {
+	const ephemeral = 45000
+	for _, p := range []packet{
+		{chain: "OUTPUT", sport: ephemeral, dport: port, cgroup: client},
+		{chain: "INPUT", sport: ephemeral, dport: port},
+		{chain: "OUTPUT", sport: port, dport: ephemeral, cgroup: server},
+		{chain: "INPUT", sport: port, dport: ephemeral},
+	} {
+		if dropped(rules, p) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPartitionDirection(t *testing.T) {
+	const (
+		target     = "booga-1/data-0-0"
+		targetPort = 29000
+		peer       = "booga-1/data-0-1"
+		peerPort   = 29001
+	)
+
+	inbound := iptablesInboundRules(targetPort)
+	if !connects(inbound, target, peer, peerPort) {
+		t.Error("inbound partition: target should reach peer")
+	}
+	if connects(inbound, peer, target, targetPort) {
+		t.Error("inbound partition: peer should not reach target")
+	}
+
+	full := iptablesPartitionRules(targetPort, target)
+	if connects(full, target, peer, peerPort) {
+		t.Error("partition: target should not reach peer")
+	}
+	if connects(full, peer, target, targetPort) {
+		t.Error("partition: peer should not reach target")
+	}
+	if !connects(full, peer, "booga-1/data-0-2", 29002) {
+		t.Error("partition: other services should be connected")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package booga
+
+import (
+	"os"
+	"syscall"
+)
+
+// pauseProcess stops process group of p until resumeProcess.
+func pauseProcess(p *os.Process) error {
+	return signalGroup(p, syscall.SIGSTOP)
+}
+
+// resumeProcess continues process group of p stopped by pauseProcess.
+func resumeProcess(p *os.Process) error {
+	return signalGroup(p, syscall.SIGCONT)
+}
@@ -0,0 +1,15 @@
+package booga
+
+import (
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+func pauseProcess(p *os.Process) error {
+	return xerrors.New("pausing processes is not supported on windows")
+}
+
+func resumeProcess(p *os.Process) error {
+	return xerrors.New("pausing processes is not supported on windows")
+}
@@ -0,0 +1,17 @@
+package booga
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setParentDeathSignal makes kernel kill cmd process if parent dies.
+//
+// Signal is delivered when thread that started process exits, which
+// is usually the same as process exit for Go programs.
+func setParentDeathSignal(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Pdeathsig = syscall.SIGKILL
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package booga
+
+import "os/exec"
+
+// setParentDeathSignal is no-op, parent death signal is Linux-specific.
+func setParentDeathSignal(cmd *exec.Cmd) {}
@@ -0,0 +1,36 @@
+package booga
+
+import (
+	"context"
+	"path/filepath"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// HotBackup creates physical backup of running node to dir using
+// createBackup command of Percona Server for MongoDB.
+func (c *Cluster) HotBackup(ctx context.Context, name, dir string) error {
+	uri, err := c.serviceURI(name)
+	if err != nil {
+		return err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return xerrors.Errorf("abs: %w", err)
+	}
+
+	return withClient(ctx, uri, func(client *mongo.Client) error {
+		if err := requireDistribution(ctx, client, "hot backup", Percona); err != nil {
+			return err
+		}
+		if err := client.Database("admin").
+			RunCommand(ctx, bson.M{"createBackup": 1, "backupDir": dir}).
+			Err(); err != nil {
+			return xerrors.Errorf("createBackup: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,243 @@
+package booga
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// snapshotsDir returns directory of snapshots in oplog archive directory.
+func (c *Cluster) snapshotsDir() string {
+	return filepath.Join(c.oplogArchiveDir, "snapshots")
+}
+
+// Snapshot copies state of every stateful node to snapshots directory in
+// Config.OplogArchiveDir and returns snapshot time.
+//
+// Nodes are locked for writes one by one, so snapshot is not consistent
+// across shards until oplog is replayed by RestoreToTime.
+func (c *Cluster) Snapshot(ctx context.Context) (time.Time, error) {
+	if c.oplogArchiveDir == "" {
+		return time.Time{}, xerrors.New("OplogArchiveDir is not set")
+	}
+	if err := ensureDir(c.snapshotsDir()); err != nil {
+		return time.Time{}, xerrors.Errorf("ensure: %w", err)
+	}
+
+	now := time.Now()
+	dst := filepath.Join(c.snapshotsDir(), strconv.FormatInt(now.UnixNano(), 10))
+	tmp := dst + ".tmp"
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	for _, n := range c.statefulNodes() {
+		if err := c.snapshotNode(ctx, n, filepath.Join(tmp, n.Name)); err != nil {
+			return time.Time{}, xerrors.Errorf("snapshot %s: %w", n.Name, err)
+		}
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return time.Time{}, xerrors.Errorf("rename: %w", err)
+	}
+	c.log.Info("Snapshot saved", zap.String("dir", dst))
+
+	return now, nil
+}
+
+// latestSnapshot returns directory of latest snapshot taken not after t.
+func latestSnapshot(dir string, t time.Time) (string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", xerrors.Errorf("read: %w", err)
+	}
+
+	var times []int64
+	for _, e := range entries {
+		ns, err := strconv.ParseInt(e.Name(), 10, 64)
+		if err != nil || !e.IsDir() {
+			// Temporary or foreign entry.
+			continue
+		}
+		if ns <= t.UnixNano() {
+			times = append(times, ns)
+		}
+	}
+	if len(times) == 0 {
+		return "", xerrors.Errorf("no snapshot before %s", t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	return filepath.Join(dir, strconv.FormatInt(times[len(times)-1], 10)), nil
+}
+
+// readBSON calls f on every document of BSON sequence.
+func readBSON(r io.Reader, f func(doc bson.Raw) error) error {
+	br := bufio.NewReader(r)
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(br, size[:]); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return xerrors.Errorf("read size: %w", err)
+		}
+		n := int(binary.LittleEndian.Uint32(size[:]))
+		if n < len(size) {
+			return xerrors.Errorf("invalid document size %d", n)
+		}
+
+		doc := make([]byte, n)
+		copy(doc, size[:])
+		if _, err := io.ReadFull(br, doc[len(size):]); err != nil {
+			return xerrors.Errorf("read document: %w", err)
+		}
+		if err := f(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// oplogEntry is subset of oplog entry fields used for replay.
+type oplogEntry struct {
+	TS   primitive.Timestamp `bson:"ts"`
+	Wall time.Time           `bson:"wall"`
+	Op   string              `bson:"op"`
+}
+
+// replayOplog applies archived oplog entries of shard that are newer than
+// shard state and not after t.
+func (c *Cluster) replayOplog(ctx context.Context, shardID int, t time.Time) (int, error) {
+	rsName := c.shardReplicaSet(shardID)
+	f, err := os.Open(oplogArchivePath(c.oplogArchiveDir, rsName))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, xerrors.Errorf("open: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var applied int
+	err = withClient(ctx, c.shardURI(shardID), func(client *mongo.Client) error {
+		last, err := lastOplogTimestamp(ctx, client.Database("local").Collection("oplog.rs"))
+		if err != nil {
+			return xerrors.Errorf("last entry: %w", err)
+		}
+
+		return readBSON(f, func(doc bson.Raw) error {
+			var e oplogEntry
+			if err := bson.Unmarshal(doc, &e); err != nil {
+				return xerrors.Errorf("decode: %w", err)
+			}
+			if e.Op == "n" || primitive.CompareTimestamp(e.TS, last) <= 0 {
+				// No-op or already present in snapshot.
+				return nil
+			}
+			if e.Wall.After(t) || (e.Wall.IsZero() && time.Unix(int64(e.TS.T), 0).After(t)) {
+				return nil
+			}
+			if err := client.Database("admin").
+				RunCommand(ctx, bson.M{"applyOps": bson.A{doc}}).
+				Err(); err != nil {
+				return xerrors.Errorf("applyOps %v: %w", e.TS, err)
+			}
+			applied++
+			return nil
+		})
+	})
+
+	return applied, err
+}
+
+// RestoreToTime restores latest snapshot taken not after t and replays
+// archived oplog of every shard up to t.
+//
+// Every service is restarted. Oplog of config server is not archived, so
+// metadata is restored as of snapshot time.
+func (c *Cluster) RestoreToTime(ctx context.Context, t time.Time) error {
+	if c.oplogArchiveDir == "" {
+		return xerrors.New("OplogArchiveDir is not set")
+	}
+	snapshot, err := latestSnapshot(c.snapshotsDir(), t)
+	if err != nil {
+		return xerrors.Errorf("snapshot: %w", err)
+	}
+	c.log.Info("Restoring snapshot", zap.String("dir", snapshot), zap.Time("t", t))
+
+	nodes := c.statefulNodes()
+	names := []string{c.routingName()}
+	for _, n := range nodes {
+		names = append(names, n.Name)
+	}
+	for _, name := range names {
+		if err := c.Kill(name); err != nil {
+			return xerrors.Errorf("kill: %w", err)
+		}
+	}
+	for _, name := range names {
+		if err := c.waitServiceState(ctx, name, ServiceStopped); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range nodes {
+		dir := filepath.Join(c.dir, n.Name)
+		if err := os.RemoveAll(dir); err != nil {
+			return xerrors.Errorf("remove %s: %w", n.Name, err)
+		}
+		if err := copyDir(dir, filepath.Join(snapshot, n.Name)); err != nil {
+			return xerrors.Errorf("copy %s: %w", n.Name, err)
+		}
+	}
+
+	// Starting stateful nodes first, so router observes restored state.
+	for _, name := range names[1:] {
+		if err := c.Restart(name); err != nil {
+			return xerrors.Errorf("restart: %w", err)
+		}
+	}
+	for _, name := range names[1:] {
+		if err := c.waitServiceState(ctx, name, ServiceReady); err != nil {
+			return err
+		}
+	}
+	if err := c.Restart(c.routingName()); err != nil {
+		return xerrors.Errorf("restart: %w", err)
+	}
+	if err := c.waitServiceState(ctx, c.routingName(), ServiceReady); err != nil {
+		return err
+	}
+
+	for shardID := 0; shardID < c.shards; shardID++ {
+		// Primary can be elected some time after restart.
+		if err := c.waitStatus(ctx, shardID, func(s *ReplSetStatus) error {
+			if _, ok := s.primaryOptime(); !ok {
+				return xerrors.New("no primary")
+			}
+			return nil
+		}); err != nil {
+			return xerrors.Errorf("wait primary: %w", err)
+		}
+
+		applied, err := c.replayOplog(ctx, shardID, t)
+		if err != nil {
+			return xerrors.Errorf("replay %s: %w", c.shardReplicaSet(shardID), err)
+		}
+		c.log.Info("Oplog replayed",
+			zap.String("rs", c.shardReplicaSet(shardID)),
+			zap.Int("entries", applied),
+		)
+	}
+
+	return nil
+}
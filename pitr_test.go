@@ -0,0 +1,71 @@
+package booga
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestReadBSON(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 3; i++ {
+		doc, err := bson.Marshal(bson.M{"i": i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(doc)
+	}
+
+	var got []int32
+	if err := readBSON(&buf, func(doc bson.Raw) error {
+		got = append(got, doc.Lookup("i").Int32())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != 0 || got[2] != 2 {
+		t.Errorf("got %v", got)
+	}
+
+	if err := readBSON(bytes.NewReader([]byte{10, 0, 0, 0, 1}), func(doc bson.Raw) error {
+		return nil
+	}); err == nil {
+		t.Error("truncated document: expected error")
+	}
+}
+
+func TestLatestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "booga-snapshots-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	base := time.Unix(1000, 0)
+	for _, name := range []string{
+		strconv.FormatInt(base.UnixNano(), 10),
+		strconv.FormatInt(base.Add(time.Minute).UnixNano(), 10),
+		strconv.FormatInt(base.Add(time.Hour).UnixNano(), 10) + ".tmp",
+	} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := latestSnapshot(dir, base.Add(time.Hour*2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(dir, strconv.FormatInt(base.Add(time.Minute).UnixNano(), 10)); got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+	if _, err := latestSnapshot(dir, base.Add(-time.Second)); err == nil {
+		t.Error("expected error for time before first snapshot")
+	}
+}
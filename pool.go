@@ -0,0 +1,146 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// poolEntry is shared running cluster.
+type poolEntry struct {
+	key     string
+	cluster *Cluster
+	refs    int
+	leases  int // total count of leases, used for database names
+
+	started chan struct{} // closed when Start returns
+	err     error         // result of Start, set before started is closed
+}
+
+var pool struct {
+	mux   sync.Mutex
+	entry *poolEntry
+}
+
+// poolKey returns identity of cluster specification.
+func poolKey(spec Config) string {
+	return fmt.Sprintf("%s|%s|%s|%d|%d|%v",
+		spec.Mongod, spec.Mongos, spec.Dir, spec.Shards, spec.Replicas, spec.MaxCacheGB,
+	)
+}
+
+// Lease is reference to shared cluster acquired by Acquire.
+type Lease struct {
+	Cluster *Cluster
+	// DB is database name unique to lease, should be used for isolation
+	// from other tests sharing the cluster. Database is dropped on
+	// Release.
+	DB string
+
+	entry *poolEntry
+	once  sync.Once
+}
+
+// Acquire returns lease of shared cluster with given specification,
+// starting cluster if needed. Lease should be released by Release, and
+// cluster is stopped when last lease is released.
+//
+// Because cluster ports are fixed, only one specification can be running
+// at a time.
+func Acquire(ctx context.Context, spec Config) (*Lease, error) {
+	key := poolKey(spec)
+
+	pool.mux.Lock()
+	e := pool.entry
+	if e != nil && e.key != key {
+		pool.mux.Unlock()
+		return nil, xerrors.New("cluster with different specification is already running")
+	}
+	if e == nil {
+		e = startPoolEntry(key, spec)
+		pool.entry = e
+	}
+	e.refs++
+	e.leases++
+	lease := &Lease{
+		Cluster: e.cluster,
+		DB:      fmt.Sprintf("%s_%d", e.cluster.db, e.leases),
+		entry:   e,
+	}
+	pool.mux.Unlock()
+
+	select {
+	case <-e.started:
+		if e.err != nil {
+			lease.Release()
+			return nil, xerrors.Errorf("start: %w", e.err)
+		}
+		return lease, nil
+	case <-ctx.Done():
+		lease.Release()
+		return nil, ctx.Err()
+	}
+}
+
+func startPoolEntry(key string, spec Config) *poolEntry {
+	e := &poolEntry{
+		key:     key,
+		cluster: New(spec),
+		started: make(chan struct{}),
+	}
+	go func() {
+		defer close(e.started)
+		e.err = e.cluster.Start(context.Background())
+	}()
+
+	return e
+}
+
+// Release drops lease database and releases reference to shared cluster.
+// Cluster is stopped when last reference is released.
+//
+// Release is idempotent.
+func (l *Lease) Release() {
+	l.once.Do(func() {
+		e := l.entry
+		select {
+		case <-e.started:
+			if e.err != nil {
+				break
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			if err := withClient(ctx, e.cluster.routerURI(), func(client *mongo.Client) error {
+				return client.Database(l.DB).Drop(ctx)
+			}); err != nil {
+				e.cluster.log.Warn("Failed to drop lease database", zap.Error(err))
+			}
+			cancel()
+		default:
+		}
+
+		pool.mux.Lock()
+		e.refs--
+		last := e.refs == 0
+		if last {
+			pool.entry = nil
+		}
+		pool.mux.Unlock()
+
+		if last {
+			<-e.started
+			if e.err != nil {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			defer cancel()
+			if err := e.cluster.Stop(ctx); err != nil {
+				e.cluster.log.Warn("Failed to stop shared cluster", zap.Error(err))
+			}
+		}
+	})
+}
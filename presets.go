@@ -0,0 +1,46 @@
+package booga
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// preset returns configuration with defaults shared by presets. Binaries
+// are discovered and clusters of concurrent processes don't conflict.
+func preset() Config {
+	return Config{
+		Log:          zap.NewNop(),
+		Dir:          filepath.Join(os.TempDir(), "booga"),
+		SetupTimeout: time.Minute,
+		Coordinate:   true,
+	}
+}
+
+// Minimal returns configuration of the smallest cluster: single shard
+// with single member. Fields can be adjusted before New.
+func Minimal() Config {
+	opt := preset()
+	opt.Shards = 1
+	opt.Replicas = 1
+	return opt
+}
+
+// ProductionLike returns configuration of cluster with production
+// topology: three shards of three members each, config server replica set
+// of three members, access control with default Credentials, majority
+// read and write concerns by default and start retries. Requires 4.4 for
+// default concerns.
+func ProductionLike() Config {
+	opt := preset()
+	opt.Shards = 3
+	opt.Replicas = 3
+	opt.ConfigReplicas = 3
+	opt.Auth = &Credentials{}
+	opt.MajorityConcerns = true
+	opt.SetupTimeout = time.Minute * 2
+	opt.StartRetries = 2
+	return opt
+}
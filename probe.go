@@ -0,0 +1,37 @@
+package booga
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ProbeHandler returns HTTP handler of health probes:
+//
+//	GET /healthz  200 while cluster is running
+//	GET /readyz   200 after OnSetup and OnReady completed, while every
+//	              service is ready, 503 otherwise
+func (c *Cluster) ProbeHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !c.isReady() {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		var notReady []string
+		for _, s := range c.Services() {
+			if s.State != ServiceReady {
+				notReady = append(notReady, s.Name)
+			}
+		}
+		if len(notReady) > 0 {
+			http.Error(w, "not ready: "+strings.Join(notReady, ", "), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
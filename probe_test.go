@@ -0,0 +1,28 @@
+package booga
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeHandler(t *testing.T) {
+	c := New(Config{Shards: 1, Replicas: 1})
+	h := c.ProbeHandler()
+	status := func(path string) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	if code := status("/healthz"); code != http.StatusOK {
+		t.Errorf("healthz: %d", code)
+	}
+	if code := status("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("readyz before ready: %d", code)
+	}
+	c.markReady()
+	if code := status("/readyz"); code != http.StatusOK {
+		t.Errorf("readyz: %d", code)
+	}
+}
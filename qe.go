@@ -0,0 +1,173 @@
+package booga
+
+import (
+	"context"
+	"crypto/rand"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/xerrors"
+)
+
+// masterKeySize is size of local KMS master key.
+const masterKeySize = 96
+
+// NewMasterKey generates random local KMS master key.
+func NewMasterKey() ([]byte, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, xerrors.Errorf("read: %w", err)
+	}
+	return key, nil
+}
+
+// QueryableEncryption configures Queryable Encryption with local KMS
+// provider.
+type QueryableEncryption struct {
+	// KeyVaultNamespace is "db.collection" of key vault,
+	// "encryption.__keyVault" by default.
+	KeyVaultNamespace string
+	// MasterKey is local KMS master key, see NewMasterKey.
+	MasterKey []byte
+}
+
+func (q QueryableEncryption) keyVaultNamespace() string {
+	if q.KeyVaultNamespace == "" {
+		return "encryption.__keyVault"
+	}
+	return q.KeyVaultNamespace
+}
+
+func (q QueryableEncryption) kmsProviders() map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"local": {"key": q.MasterKey},
+	}
+}
+
+// EncryptedField describes encrypted field of collection.
+type EncryptedField struct {
+	Path     string // e.g. "patient.ssn"
+	BSONType string // e.g. "string"
+	// KeyID is data key of field, created in key vault if nil.
+	KeyID *primitive.Binary
+	// Queries enabled on field, e.g. bson.M{"queryType": "equality"}.
+	Queries []bson.M
+}
+
+// encryptedFields returns encryptedFields option of create command.
+func encryptedFields(fields []EncryptedField) bson.M {
+	docs := bson.A{}
+	for _, f := range fields {
+		doc := bson.M{
+			"path":     f.Path,
+			"bsonType": f.BSONType,
+			"keyId":    *f.KeyID,
+		}
+		if len(f.Queries) > 0 {
+			doc["queries"] = f.Queries
+		}
+		docs = append(docs, doc)
+	}
+	return bson.M{"fields": docs}
+}
+
+// stateCollections returns names of metadata collections of encrypted
+// collection, that are created along with it.
+func stateCollections(name string) []string {
+	return []string{
+		"enxcol_." + name + ".esc",
+		"enxcol_." + name + ".ecoc",
+	}
+}
+
+// SetupKeyVault creates key vault collection with unique index on key
+// alternate names.
+func (c *Cluster) SetupKeyVault(ctx context.Context, qe QueryableEncryption) error {
+	db, coll, err := splitNamespace(qe.keyVaultNamespace())
+	if err != nil {
+		return xerrors.Errorf("key vault: %w", err)
+	}
+
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		if _, err := client.Database(db).Collection(coll).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "keyAltNames", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"keyAltNames": bson.M{"$exists": true}}),
+		}); err != nil {
+			return xerrors.Errorf("create index: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// CreateEncryptedCollection creates collection with encrypted fields in
+// cluster database as drivers do: state collections first, then
+// collection with encryptedFields and index on __safeContent__, creating
+// missing data keys in key vault. Returns encryptedFields of collection.
+//
+// Creating data keys requires libmongocrypt and "cse" build tag.
+// Requires MongoDB 7.0 or newer.
+func (c *Cluster) CreateEncryptedCollection(ctx context.Context, qe QueryableEncryption, name string, fields []EncryptedField) (bson.M, error) {
+	fields = append([]EncryptedField(nil), fields...)
+
+	var ef bson.M
+	if err := withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		info, err := getBuildInfo(ctx, client)
+		if err != nil {
+			return xerrors.Errorf("version: %w", err)
+		}
+		if !info.AtLeast(7, 0) {
+			return xerrors.Errorf("queryable encryption is not supported by %s", info.Version)
+		}
+
+		if err := createDataKeys(ctx, client, qe, fields); err != nil {
+			return xerrors.Errorf("data keys: %w", err)
+		}
+		db := client.Database(c.db)
+		for _, state := range stateCollections(name) {
+			if err := db.RunCommand(ctx, bson.D{
+				{Key: "create", Value: state},
+				{Key: "clusteredIndex", Value: bson.M{"key": bson.M{"_id": 1}, "unique": true}},
+			}).Err(); err != nil {
+				return xerrors.Errorf("create %s: %w", state, err)
+			}
+		}
+		ef = encryptedFields(fields)
+		if err := db.RunCommand(ctx, bson.D{
+			{Key: "create", Value: name},
+			{Key: "encryptedFields", Value: ef},
+		}).Err(); err != nil {
+			return xerrors.Errorf("create: %w", err)
+		}
+		if _, err := db.Collection(name).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: "__safeContent__", Value: 1}},
+		}); err != nil {
+			return xerrors.Errorf("create index: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ef, nil
+}
+
+// AutoEncryptionOptions returns auto encryption options for application
+// client that use key vault of cluster.
+//
+// Encrypted fields are read by driver from collection metadata on server,
+// so collections should be created by CreateEncryptedCollection.
+// Automatic Queryable Encryption requires mongo-driver 1.12 or newer with
+// libmongocrypt, building application with such driver upgrades driver
+// of this package too.
+func (c *Cluster) AutoEncryptionOptions(qe QueryableEncryption) *options.AutoEncryptionOptions {
+	return options.AutoEncryption().
+		SetKeyVaultNamespace(qe.keyVaultNamespace()).
+		SetKmsProviders(qe.kmsProviders()).
+		SetKeyVaultClientOptions(options.Client().ApplyURI(c.routerURI()))
+}
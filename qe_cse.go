@@ -0,0 +1,39 @@
+//go:build cse
+// +build cse
+
+package booga
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/xerrors"
+)
+
+// createDataKeys creates data key for every field without one.
+func createDataKeys(ctx context.Context, client *mongo.Client, qe QueryableEncryption, fields []EncryptedField) error {
+	ce, err := mongo.NewClientEncryption(client, options.ClientEncryption().
+		SetKeyVaultNamespace(qe.keyVaultNamespace()).
+		SetKmsProviders(qe.kmsProviders()),
+	)
+	if err != nil {
+		return xerrors.Errorf("client encryption: %w", err)
+	}
+	defer func() {
+		_ = ce.Close(ctx)
+	}()
+
+	for i := range fields {
+		if fields[i].KeyID != nil {
+			continue
+		}
+		id, err := ce.CreateDataKey(ctx, "local")
+		if err != nil {
+			return xerrors.Errorf("create %s: %w", fields[i].Path, err)
+		}
+		fields[i].KeyID = &id
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build !cse
+// +build !cse
+
+package booga
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// createDataKeys returns error if any field has no data key, because
+// client-side encryption is not enabled.
+func createDataKeys(ctx context.Context, client *mongo.Client, qe QueryableEncryption, fields []EncryptedField) error {
+	for _, f := range fields {
+		if f.KeyID == nil {
+			return xerrors.Errorf("no data key for %s: add cse build tag to create data keys", f.Path)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEncryptedFields(t *testing.T) {
+	key := primitive.Binary{Subtype: 4, Data: make([]byte, 16)}
+	got := encryptedFields([]EncryptedField{
+		{Path: "ssn", BSONType: "string", KeyID: &key, Queries: []bson.M{{"queryType": "equality"}}},
+		{Path: "patient.notes", BSONType: "string", KeyID: &key},
+	})
+	expected := bson.M{"fields": bson.A{
+		bson.M{"path": "ssn", "bsonType": "string", "keyId": key, "queries": []bson.M{{"queryType": "equality"}}},
+		bson.M{"path": "patient.notes", "bsonType": "string", "keyId": key},
+	}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected encryptedFields %v", got)
+	}
+	if _, err := bson.Marshal(got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := stateCollections("patients"); !reflect.DeepEqual(got, []string{"enxcol_.patients.esc", "enxcol_.patients.ecoc"}) {
+		t.Errorf("unexpected state collections %v", got)
+	}
+}
@@ -0,0 +1,107 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"golang.org/x/xerrors"
+)
+
+// mirrorReadsArgs returns mongod arguments that set mirrorReads sampling
+// rate, requires 4.4.
+func mirrorReadsArgs(samplingRate float64) []string {
+	if samplingRate <= 0 {
+		return nil
+	}
+	return []string{"--setParameter", fmt.Sprintf("mirrorReads={samplingRate: %v}", samplingRate)}
+}
+
+// eachService calls f with client directly connected to every ready
+// service of given type.
+func (c *Cluster) eachService(ctx context.Context, t ServerType, f func(s ServiceInfo, client *mongo.Client) error) error {
+	for _, s := range c.Services() {
+		if s.Type != t || s.State != ServiceReady {
+			continue
+		}
+		uri, err := c.serviceURI(s.Name)
+		if err != nil {
+			return err
+		}
+		if err := withClient(ctx, uri, func(client *mongo.Client) error {
+			return f(s, client)
+		}); err != nil {
+			return xerrors.Errorf("%s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// SetMirrorReads sets fraction of eligible reads that every primary
+// mirrors to electable secondaries to warm up their cache, from 0 to 1.
+// Requires 4.4.
+func (c *Cluster) SetMirrorReads(ctx context.Context, samplingRate float64) error {
+	return c.eachService(ctx, DataServer, func(_ ServiceInfo, client *mongo.Client) error {
+		return client.Database("admin").RunCommand(ctx, bson.D{
+			{Key: "setParameter", Value: 1},
+			{Key: "mirrorReads", Value: bson.M{"samplingRate": samplingRate}},
+		}).Err()
+	})
+}
+
+// MirroredReads is mirrored reads metrics of shard member.
+type MirroredReads struct {
+	Seen int64 `bson:"seen"` // operations that support mirroring
+	Sent int64 `bson:"sent"` // operations mirrored to secondaries
+}
+
+// MirroredReads returns mirrored reads metrics of every shard member by
+// service name. Requires 4.4.
+func (c *Cluster) MirroredReads(ctx context.Context) (map[string]MirroredReads, error) {
+	metrics := map[string]MirroredReads{}
+	if err := c.eachService(ctx, DataServer, func(s ServiceInfo, client *mongo.Client) error {
+		var reply struct {
+			MirroredReads MirroredReads `bson:"mirroredReads"`
+		}
+		if err := client.Database("admin").
+			RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}, {Key: "mirroredReads", Value: 1}}).
+			Decode(&reply); err != nil {
+			return xerrors.Errorf("serverStatus: %w", err)
+		}
+		metrics[s.Name] = reply.MirroredReads
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// SetReadHedging enables or disables hedged reads on routing server.
+// Hedging is enabled by default since 4.4.
+func (c *Cluster) SetReadHedging(ctx context.Context, enabled bool) error {
+	mode := "off"
+	if enabled {
+		mode = "on"
+	}
+	return c.eachService(ctx, RoutingServer, func(_ ServiceInfo, client *mongo.Client) error {
+		return client.Database("admin").RunCommand(ctx, bson.D{
+			{Key: "setParameter", Value: 1},
+			{Key: "readHedgingMode", Value: mode},
+		}).Err()
+	})
+}
+
+// HedgedClient returns new client connected to routing server with
+// nearest read preference and hedged reads, so routing server sends
+// every read to two members of shard and uses first response.
+//
+// Client should be disconnected by caller.
+func (c *Cluster) HedgedClient(ctx context.Context) (*mongo.Client, error) {
+	return connect(ctx, c.routerURI(), options.Client().SetReadPreference(
+		readpref.Nearest(readpref.WithHedgeEnabled(true)),
+	))
+}
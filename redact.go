@@ -0,0 +1,80 @@
+package booga
+
+import "regexp"
+
+// redacted replaces values of redacted log attributes.
+const redacted = "[REDACTED]"
+
+// uriCredentials matches user info of connection string.
+var uriCredentials = regexp.MustCompile(`(mongodb(?:\+srv)?://)[^/@\s"]+@`)
+
+// LogRedaction configures redaction of service log entries before they
+// are logged, kept or exported.
+type LogRedaction struct {
+	// Attributes are names of attributes to redact at any depth, e.g.
+	// "command" or "doc".
+	Attributes []string
+	// Credentials enables removal of user info from connection strings
+	// in attribute values.
+	Credentials bool
+}
+
+// redactor returns redactor of configuration, nil if r is nil.
+func (r *LogRedaction) redactor() *logRedactor {
+	if r == nil {
+		return nil
+	}
+	names := make(map[string]struct{}, len(r.Attributes))
+	for _, name := range r.Attributes {
+		names[name] = struct{}{}
+	}
+	return &logRedactor{names: names, credentials: r.Credentials}
+}
+
+// logRedactor applies LogRedaction to log entries.
+type logRedactor struct {
+	names       map[string]struct{}
+	credentials bool
+}
+
+func (r *logRedactor) apply(e *Entry) {
+	if r == nil {
+		return
+	}
+	if r.credentials {
+		// Messages of legacy plaintext logs have all details.
+		e.Message = redactCredentials(e.Message)
+	}
+	r.redactMap(e.Attributes)
+}
+
+// redactCredentials removes user info from connection strings in s.
+func redactCredentials(s string) string {
+	return uriCredentials.ReplaceAllString(s, "${1}"+redacted+"@")
+}
+
+func (r *logRedactor) redactMap(m map[string]interface{}) {
+	for k, v := range m {
+		if _, ok := r.names[k]; ok {
+			m[k] = redacted
+			continue
+		}
+		m[k] = r.redactValue(v)
+	}
+}
+
+func (r *logRedactor) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		r.redactMap(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = r.redactValue(v[i])
+		}
+	case string:
+		if r.credentials {
+			return redactCredentials(v)
+		}
+	}
+	return v
+}
@@ -0,0 +1,85 @@
+package booga
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+// Cross-process registry reserves port ranges and directories for
+// clusters, so multiple processes (e.g. parallel "go test" binaries) can
+// run clusters simultaneously.
+//
+// Every reservation is a slot, protected by file lock in well-known
+// directory. Locks are released by OS if process dies.
+const (
+	slotPorts = 2000 // size of port range of single slot
+	maxSlots  = 16
+)
+
+// registryDir returns well-known directory of registry.
+func registryDir() string {
+	return filepath.Join(os.TempDir(), "booga")
+}
+
+// slot is reservation of port range and directory.
+type slot struct {
+	ID int
+	f  *os.File
+}
+
+// PortOffset returns offset that should be added to every port.
+func (s *slot) PortOffset() int {
+	return s.ID * slotPorts
+}
+
+// Dir returns directory name reserved by slot.
+func (s *slot) Dir() string {
+	return fmt.Sprintf("slot-%d", s.ID)
+}
+
+// acquireSlot reserves first free slot.
+func acquireSlot() (*slot, error) {
+	dir := registryDir()
+	if err := ensureDir(dir); err != nil {
+		return nil, xerrors.Errorf("ensure: %w", err)
+	}
+
+	for id := 0; id < maxSlots; id++ {
+		name := filepath.Join(dir, fmt.Sprintf("slot-%d.lock", id))
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return nil, xerrors.Errorf("open: %w", err)
+		}
+		ok, err := tryLock(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, xerrors.Errorf("lock: %w", err)
+		}
+		if !ok {
+			_ = f.Close()
+			continue
+		}
+
+		// Writing pid of owner for debugging purposes.
+		if err := f.Truncate(0); err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+		}
+
+		return &slot{ID: id, f: f}, nil
+	}
+
+	return nil, xerrors.Errorf("all %d slots in %s are busy", maxSlots, dir)
+}
+
+// Release releases reservation.
+func (s *slot) Release() error {
+	if err := unlock(s.f); err != nil {
+		_ = s.f.Close()
+		return xerrors.Errorf("unlock: %w", err)
+	}
+
+	return s.f.Close()
+}
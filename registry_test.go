@@ -0,0 +1,49 @@
+package booga
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAcquireSlot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "booga-registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	prev := os.Getenv("TMPDIR")
+	if err := os.Setenv("TMPDIR", dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Setenv("TMPDIR", prev) }()
+
+	a, err := acquireSlot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := acquireSlot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID == b.ID {
+		t.Fatalf("same slot %d acquired twice", a.ID)
+	}
+	if a.PortOffset() == b.PortOffset() || a.Dir() == b.Dir() {
+		t.Fatal("slots overlap")
+	}
+
+	if err := a.Release(); err != nil {
+		t.Fatal(err)
+	}
+	c, err := acquireSlot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ID != a.ID {
+		t.Errorf("released slot %d should be reused, got %d", a.ID, c.ID)
+	}
+	_ = b.Release()
+	_ = c.Release()
+}
@@ -0,0 +1,209 @@
+package booga
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// Replica set member states.
+//
+// See https://docs.mongodb.com/manual/reference/replica-states/
+const (
+	statePrimary   = 1
+	stateSecondary = 2
+)
+
+// Optime is operation time of replica set member.
+type Optime struct {
+	TS   primitive.Timestamp `bson:"ts"`
+	Term int64               `bson:"t"`
+}
+
+// ReplSetMember is replica set member description from replSetGetStatus.
+type ReplSetMember struct {
+	ID       int     `bson:"_id"`
+	Name     string  `bson:"name"` // host:port
+	Health   float64 `bson:"health"`
+	State    int     `bson:"state"`
+	StateStr string  `bson:"stateStr"` // e.g. PRIMARY or SECONDARY
+	Uptime   int64   `bson:"uptime"`   // seconds
+	Self     bool    `bson:"self"`
+
+	Optime        Optime    `bson:"optime"`
+	OptimeDate    time.Time `bson:"optimeDate"`
+	LastHeartbeat time.Time `bson:"lastHeartbeat"`
+	PingMs        int64     `bson:"pingMs"`
+
+	SyncSourceHost string `bson:"syncSourceHost"`
+	ConfigVersion  int64  `bson:"configVersion"`
+
+	// ElectionTime and ElectionDate are set for primary.
+	ElectionTime primitive.Timestamp `bson:"electionTime"`
+	ElectionDate time.Time           `bson:"electionDate"`
+}
+
+// ElectionMetrics are metrics of election won by current primary.
+type ElectionMetrics struct {
+	Reason                string    `bson:"lastElectionReason"`
+	Date                  time.Time `bson:"lastElectionDate"`
+	ElectionTerm          int64     `bson:"electionTerm"`
+	NumVotesNeeded        int       `bson:"numVotesNeeded"`
+	PriorityAtElection    float64   `bson:"priorityAtElection"`
+	ElectionTimeoutMillis int64     `bson:"electionTimeoutMillis"`
+}
+
+// ReplSetStatus is reply of replSetGetStatus command.
+//
+// See https://docs.mongodb.com/manual/reference/command/replSetGetStatus/
+type ReplSetStatus struct {
+	Set     string    `bson:"set"`
+	Date    time.Time `bson:"date"`
+	MyState int       `bson:"myState"`
+	Term    int64     `bson:"term"`
+
+	HeartbeatIntervalMillis int64 `bson:"heartbeatIntervalMillis"`
+
+	// ElectionCandidateMetrics are set on primary, since 4.2.
+	ElectionCandidateMetrics *ElectionMetrics `bson:"electionCandidateMetrics,omitempty"`
+
+	Members []ReplSetMember `bson:"members"`
+}
+
+func replSetGetStatus(ctx context.Context, client *mongo.Client) (*ReplSetStatus, error) {
+	var status ReplSetStatus
+	if err := client.Database("admin").
+		RunCommand(ctx, bson.M{"replSetGetStatus": 1}).
+		Decode(&status); err != nil {
+		return nil, xerrors.Errorf("replSetGetStatus: %w", err)
+	}
+
+	return &status, nil
+}
+
+// ReplStatus returns replica set status of shard.
+func (c *Cluster) ReplStatus(ctx context.Context, shardID int) (ReplSetStatus, error) {
+	if shardID < 0 || shardID >= c.shards {
+		return ReplSetStatus{}, xerrors.Errorf("no shard %d", shardID)
+	}
+	var status ReplSetStatus
+	if err := withClient(ctx, c.shardURI(shardID), func(client *mongo.Client) error {
+		s, err := replSetGetStatus(ctx, client)
+		if err != nil {
+			return err
+		}
+		status = *s
+		return nil
+	}); err != nil {
+		return ReplSetStatus{}, err
+	}
+	return status, nil
+}
+
+// primaryOptime returns last applied operation time of replica set primary.
+func (s *ReplSetStatus) primaryOptime() (primitive.Timestamp, bool) {
+	for _, m := range s.Members {
+		if m.State == statePrimary {
+			return m.Optime.TS, true
+		}
+	}
+
+	return primitive.Timestamp{}, false
+}
+
+// primary returns name of primary member.
+func (s *ReplSetStatus) primary() (string, bool) {
+	for _, m := range s.Members {
+		if m.State == statePrimary {
+			return m.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// replicated reports whether every readable member applied operations
+// up to opTime.
+func (s *ReplSetStatus) replicated(opTime primitive.Timestamp) bool {
+	for _, m := range s.Members {
+		if m.State != statePrimary && m.State != stateSecondary {
+			continue
+		}
+		if primitive.CompareTimestamp(m.Optime.TS, opTime) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// waitStatus polls replSetGetStatus of shard until f returns nil.
+func (c *Cluster) waitStatus(ctx context.Context, shardID int, f func(s *ReplSetStatus) error) error {
+	return withClient(ctx, c.shardURI(shardID), func(client *mongo.Client) error {
+		b := backoff.NewConstantBackOff(time.Millisecond * 100)
+
+		return backoff.Retry(func() error {
+			status, err := replSetGetStatus(ctx, client)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return backoff.Permanent(ctx.Err())
+				default:
+					return err
+				}
+			}
+
+			return f(status)
+		}, backoff.WithContext(b, ctx))
+	})
+}
+
+// WaitReplicated blocks until every primary or secondary member of shard
+// replica set applies operations up to opTime.
+func (c *Cluster) WaitReplicated(ctx context.Context, shardID int, opTime primitive.Timestamp) error {
+	if shardID < 0 || shardID >= c.shards {
+		return xerrors.Errorf("no shard %d", shardID)
+	}
+	if err := c.waitStatus(ctx, shardID, func(s *ReplSetStatus) error {
+		if !s.replicated(opTime) {
+			return xerrors.New("not replicated")
+		}
+		return nil
+	}); err != nil {
+		return xerrors.Errorf("wait %s: %w", c.shardReplicaSet(shardID), err)
+	}
+
+	return nil
+}
+
+// WaitSecondariesCaughtUp blocks until secondaries of every shard apply
+// all operations that were applied by primary at the time of call.
+//
+// Reading from secondaries after WaitSecondariesCaughtUp is guaranteed to
+// observe all previous writes.
+func (c *Cluster) WaitSecondariesCaughtUp(ctx context.Context) error {
+	for shardID := 0; shardID < c.shards; shardID++ {
+		var opTime primitive.Timestamp
+		if err := c.waitStatus(ctx, shardID, func(s *ReplSetStatus) error {
+			ts, ok := s.primaryOptime()
+			if !ok {
+				return xerrors.New("no primary")
+			}
+			opTime = ts
+			return nil
+		}); err != nil {
+			return xerrors.Errorf("primary optime of %s: %w", c.shardReplicaSet(shardID), err)
+		}
+
+		if err := c.WaitReplicated(ctx, shardID, opTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package booga
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReplSetStatusReplicated(t *testing.T) {
+	member := func(state int, ts uint32) ReplSetMember {
+		m := ReplSetMember{State: state}
+		m.Optime.TS = primitive.Timestamp{T: ts}
+		return m
+	}
+	s := &ReplSetStatus{
+		Members: []ReplSetMember{
+			member(statePrimary, 10),
+			member(stateSecondary, 5),
+			member(8, 1), // down
+		},
+	}
+
+	opTime, ok := s.primaryOptime()
+	if !ok {
+		t.Fatal("primary not found")
+	}
+	if s.replicated(opTime) {
+		t.Error("should not be replicated")
+	}
+	if !s.replicated(primitive.Timestamp{T: 5}) {
+		t.Error("should be replicated")
+	}
+}
+
+func TestReplSetStatusDecode(t *testing.T) {
+	raw, err := bson.Marshal(bson.M{
+		"set":     "rsData0",
+		"myState": 1,
+		"term":    int64(3),
+		"electionCandidateMetrics": bson.M{
+			"lastElectionReason": "stepUpRequestSkipDryRun",
+			"electionTerm":       int64(3),
+		},
+		"members": bson.A{
+			bson.M{
+				"_id":      0,
+				"name":     "127.0.0.1:29000",
+				"health":   1.0,
+				"state":    1,
+				"stateStr": "PRIMARY",
+				"self":     true,
+				"optime":   bson.M{"ts": primitive.Timestamp{T: 10, I: 1}, "t": int64(3)},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s ReplSetStatus
+	if err := bson.Unmarshal(raw, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Set != "rsData0" || s.MyState != statePrimary || s.Term != 3 {
+		t.Errorf("unexpected status %+v", s)
+	}
+	if m := s.ElectionCandidateMetrics; m == nil || m.Reason != "stepUpRequestSkipDryRun" {
+		t.Errorf("unexpected election metrics %+v", m)
+	}
+	if len(s.Members) != 1 || !s.Members[0].Self || s.Members[0].Optime.Term != 3 {
+		t.Errorf("unexpected members %+v", s.Members)
+	}
+}
@@ -0,0 +1,133 @@
+package booga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Phase is single recorded startup phase.
+type Phase struct {
+	Name     string        `json:"name"`
+	Node     string        `json:"node,omitempty"` // service or replica set
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"` // zero if phase is not finished
+	Error    string        `json:"error,omitempty"`
+}
+
+// StartupReport is timeline of cluster startup.
+type StartupReport struct {
+	Start time.Time `json:"start"`
+	// Duration of startup, zero if cluster is not ready.
+	Duration time.Duration `json:"duration"`
+	Phases   []Phase       `json:"phases"`
+	// Binaries used by cluster, recorded if Config.InspectBinaries is set.
+	Binaries []Binary `json:"binaries,omitempty"`
+}
+
+// JSON returns JSON representation of report.
+func (r StartupReport) JSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// String returns compact human-readable summary of report, one phase
+// per line with offset from startup start and duration.
+func (r StartupReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "startup %s\n", r.Duration.Round(time.Millisecond))
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, p := range r.Phases {
+		d := "running"
+		if p.Duration > 0 || p.Error != "" {
+			d = p.Duration.Round(time.Millisecond).String()
+		}
+		_, _ = fmt.Fprintf(w, "+%s\t%s\t%s\t%s\t%s\n",
+			p.Start.Sub(r.Start).Round(time.Millisecond), d, p.Node, p.Name, p.Error,
+		)
+	}
+	for _, bin := range r.Binaries {
+		_, _ = fmt.Fprintf(w, "binary\t%s\t%s\t%s\n", bin.Path, bin.Version, bin.SHA256)
+	}
+	_ = w.Flush()
+
+	return b.String()
+}
+
+// timeline records startup phases.
+type timeline struct {
+	mux    sync.Mutex
+	report StartupReport
+}
+
+func (t *timeline) begin(name, node string) int {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.report.Phases = append(t.report.Phases, Phase{
+		Name:  name,
+		Node:  node,
+		Start: time.Now(),
+	})
+	return len(t.report.Phases) - 1
+}
+
+func (t *timeline) addBinary(b Binary) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.report.Binaries = append(t.report.Binaries, b)
+}
+
+func (t *timeline) end(i int, err error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	p := &t.report.Phases[i]
+	p.Duration = time.Since(p.Start)
+	if err != nil {
+		p.Error = err.Error()
+	}
+}
+
+// phase starts startup phase that is traced as span and recorded to
+// startup report. Returned function ends phase and is idempotent.
+func (c *Cluster) phase(ctx context.Context, name, node string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	if node != "" {
+		attrs = append(attrs, attribute.String("booga.service", node))
+	}
+	ctx, span := c.startSpan(ctx, name, attrs...)
+	i := c.timeline.begin(name, node)
+
+	var once sync.Once
+	return ctx, func(err error) {
+		once.Do(func() {
+			c.timeline.end(i, err)
+			endSpan(span, err)
+		})
+	}
+}
+
+// StartupReport returns timeline of cluster startup phases. Report can be
+// requested while cluster is starting.
+func (c *Cluster) StartupReport() StartupReport {
+	c.timeline.mux.Lock()
+	defer c.timeline.mux.Unlock()
+
+	r := c.timeline.report
+	r.Phases = append([]Phase(nil), r.Phases...)
+	r.Binaries = append([]Binary(nil), r.Binaries...)
+	if len(r.Phases) > 0 {
+		// First phase is startup itself.
+		r.Start = r.Phases[0].Start
+		r.Duration = r.Phases[0].Duration
+	}
+
+	return r
+}
@@ -0,0 +1,55 @@
+package booga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStartupReport(t *testing.T) {
+	c := &Cluster{tracer: newTracer(nil)}
+
+	ctx, startupDone := c.phase(context.Background(), "Startup", "")
+	_, pingDone := c.phase(ctx, "Ping", "cfg")
+	pingDone(nil)
+	_, addDone := c.phase(ctx, "addShard", "rsData0")
+	addDone(errors.New("failed"))
+	addDone(nil) // no-op
+
+	r := c.StartupReport()
+	if r.Duration != 0 {
+		t.Error("startup should not be finished")
+	}
+	startupDone(nil)
+	r = c.StartupReport()
+	if r.Duration == 0 {
+		t.Error("startup should be finished")
+	}
+	if len(r.Phases) != 3 {
+		t.Fatalf("unexpected phases: %+v", r.Phases)
+	}
+	if r.Phases[2].Error != "failed" {
+		t.Errorf("unexpected error: %q", r.Phases[2].Error)
+	}
+
+	text := r.String()
+	for _, s := range []string{"Ping", "cfg", "addShard", "rsData0", "failed"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("%q not found in:\n%s", s, text)
+		}
+	}
+
+	data, err := r.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded StartupReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Phases) != 3 {
+		t.Errorf("unexpected decoded phases: %+v", decoded.Phases)
+	}
+}
@@ -0,0 +1,144 @@
+package booga
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// ReshardOptions configures Reshard.
+type ReshardOptions struct {
+	Unique           bool
+	NumInitialChunks int // optional
+	// Progress is called with progress of every shard participating in
+	// resharding, optional.
+	Progress func(p []ReshardProgress)
+	// Interval between progress reports, 1s by default.
+	Interval time.Duration
+}
+
+// ReshardProgress is resharding progress of shard from currentOp.
+//
+// See https://docs.mongodb.com/manual/reference/operator/aggregation/currentOp/#resharding-output-fields
+type ReshardProgress struct {
+	Shard            string `bson:"shard"`
+	Description      string `bson:"desc"`
+	CoordinatorState string `bson:"coordinatorState"`
+	DonorState       string `bson:"donorState"`
+	RecipientState   string `bson:"recipientState"`
+
+	ApproxDocumentsToCopy int64 `bson:"approxDocumentsToCopy"`
+	DocumentsCopied       int64 `bson:"documentsCopied"`
+	// RemainingSeconds is estimated remaining time reported by
+	// recipients, -1 if unknown.
+	RemainingSeconds int64 `bson:"remainingOperationTimeEstimatedSecs"`
+}
+
+// reshardProgress returns progress of resharding of ns.
+func reshardProgress(ctx context.Context, client *mongo.Client, ns string) ([]ReshardProgress, error) {
+	cur, err := client.Database("admin").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$currentOp", Value: bson.M{"allUsers": true, "localOps": false}}},
+		{{Key: "$match", Value: bson.M{"type": "op", "originatingCommand.reshardCollection": ns}}},
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("aggregate: %w", err)
+	}
+	var progress []ReshardProgress
+	if err := cur.All(ctx, &progress); err != nil {
+		return nil, xerrors.Errorf("all: %w", err)
+	}
+	return progress, nil
+}
+
+// Reshard reshards collection ns to new shard key and waits until
+// resharding is committed or aborted. Resharding is aborted if ctx is
+// done. Requires 5.0.
+func (c *Cluster) Reshard(ctx context.Context, ns string, key bson.D, opt ReshardOptions) error {
+	interval := opt.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	cmd := bson.D{
+		{Key: "reshardCollection", Value: ns},
+		{Key: "key", Value: key},
+	}
+	if opt.Unique {
+		cmd = append(cmd, bson.E{Key: "unique", Value: true})
+	}
+	if opt.NumInitialChunks > 0 {
+		cmd = append(cmd, bson.E{Key: "numInitialChunks", Value: opt.NumInitialChunks})
+	}
+
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		// Command blocks until resharding is committed or aborted, and
+		// is not canceled with ctx, so resharding is aborted explicitly.
+		cmdCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		result := make(chan error, 1)
+		go func() {
+			result <- client.Database("admin").RunCommand(cmdCtx, cmd).Err()
+		}()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case err := <-result:
+				if err != nil {
+					return xerrors.Errorf("reshardCollection: %w", err)
+				}
+				c.log.Info("Resharding committed", zap.String("ns", ns))
+				return nil
+			case <-ctx.Done():
+				abortCtx, abortCancel := context.WithTimeout(context.Background(), time.Second*30)
+				err := client.Database("admin").
+					RunCommand(abortCtx, bson.M{"abortReshardCollection": ns}).
+					Err()
+				abortCancel()
+				if err != nil {
+					return xerrors.Errorf("abortReshardCollection: %w", err)
+				}
+				return ctx.Err()
+			case <-ticker.C:
+				if opt.Progress == nil {
+					continue
+				}
+				progress, err := reshardProgress(ctx, client, ns)
+				if err != nil {
+					c.log.Warn("Failed to get resharding progress", zap.Error(err))
+					continue
+				}
+				opt.Progress(progress)
+			}
+		}
+	})
+}
+
+// RefineShardKey creates index on key and refines shard key of collection
+// ns to it. Key must start with current shard key. Requires 4.4.
+func (c *Cluster) RefineShardKey(ctx context.Context, ns string, key bson.D) error {
+	dbName, collName, err := splitNamespace(ns)
+	if err != nil {
+		return err
+	}
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		// Refined shard key must be supported by index.
+		if _, err := client.Database(dbName).Collection(collName).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: key,
+		}); err != nil {
+			return xerrors.Errorf("create index: %w", err)
+		}
+		if err := client.Database("admin").RunCommand(ctx, bson.D{
+			{Key: "refineCollectionShardKey", Value: ns},
+			{Key: "key", Value: key},
+		}).Err(); err != nil {
+			return xerrors.Errorf("refineCollectionShardKey: %w", err)
+		}
+		c.log.Info("Shard key refined", zap.String("ns", ns))
+		return nil
+	})
+}
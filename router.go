@@ -0,0 +1,54 @@
+package booga
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RouterOptions tunes routing servers. Zero values keep mongos defaults.
+type RouterOptions struct {
+	// LocalThreshold is latency window for selecting replica set member
+	// for reads, --localThreshold.
+	LocalThreshold time.Duration
+	// TaskExecutorPoolSize is count of task executor connection pools.
+	TaskExecutorPoolSize int
+	// ShardingTaskExecutorPoolMinSize is minimum count of connections to
+	// every shard host.
+	ShardingTaskExecutorPoolMinSize int
+
+	// Parameters are additional server parameters passed via
+	// --setParameter.
+	Parameters map[string]string
+}
+
+// args returns mongos arguments.
+func (o RouterOptions) args() []string {
+	var args []string
+	if o.LocalThreshold > 0 {
+		args = append(args, "--localThreshold", strconv.FormatInt(o.LocalThreshold.Milliseconds(), 10))
+	}
+
+	params := map[string]string{}
+	if o.TaskExecutorPoolSize > 0 {
+		params["taskExecutorPoolSize"] = strconv.Itoa(o.TaskExecutorPoolSize)
+	}
+	if o.ShardingTaskExecutorPoolMinSize > 0 {
+		params["ShardingTaskExecutorPoolMinSize"] = strconv.Itoa(o.ShardingTaskExecutorPoolMinSize)
+	}
+	for k, v := range o.Parameters {
+		params[k] = v
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--setParameter", fmt.Sprintf("%s=%s", k, params[k]))
+	}
+
+	return args
+}
@@ -0,0 +1,29 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRouterOptionsArgs(t *testing.T) {
+	if args := (RouterOptions{}).args(); len(args) != 0 {
+		t.Errorf("zero: %v", args)
+	}
+
+	args := RouterOptions{
+		LocalThreshold:                  time.Millisecond * 30,
+		TaskExecutorPoolSize:            4,
+		ShardingTaskExecutorPoolMinSize: 2,
+		Parameters:                      map[string]string{"maxTimeMSForHedgedReads": "20"},
+	}.args()
+	expected := []string{
+		"--localThreshold", "30",
+		"--setParameter", "ShardingTaskExecutorPoolMinSize=2",
+		"--setParameter", "maxTimeMSForHedgedReads=20",
+		"--setParameter", "taskExecutorPoolSize=4",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("got %v", args)
+	}
+}
@@ -0,0 +1,58 @@
+package booga
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ReplicaSetSettings overrides settings of replica set configuration,
+// zero values keep server defaults.
+//
+// See https://docs.mongodb.com/manual/reference/replica-configuration/#settings
+type ReplicaSetSettings struct {
+	ElectionTimeout   time.Duration
+	HeartbeatInterval time.Duration
+	CatchUpTimeout    time.Duration
+	// ChainingAllowed enables or disables replication from secondaries.
+	ChainingAllowed *bool
+}
+
+// document returns settings sub-document of replSetInitiate, or nil if
+// nothing is overridden.
+func (s ReplicaSetSettings) document() bson.M {
+	d := bson.M{}
+	if s.ElectionTimeout > 0 {
+		d["electionTimeoutMillis"] = s.ElectionTimeout.Milliseconds()
+	}
+	if s.HeartbeatInterval > 0 {
+		d["heartbeatIntervalMillis"] = s.HeartbeatInterval.Milliseconds()
+	}
+	if s.CatchUpTimeout > 0 {
+		d["catchUpTimeoutMillis"] = s.CatchUpTimeout.Milliseconds()
+	}
+	if s.ChainingAllowed != nil {
+		d["chainingAllowed"] = *s.ChainingAllowed
+	}
+	if len(d) == 0 {
+		return nil
+	}
+	return d
+}
+
+// replicaSetConfig returns initial configuration of replica set with
+// settings overrides applied.
+func (c *Cluster) replicaSetConfig(name string, members []bson.M) bson.M {
+	cfg := bson.M{
+		"_id":     name,
+		"members": members,
+	}
+	s, ok := c.replicaSetSettings[name]
+	if !ok {
+		s = c.replicaSetSettings[""]
+	}
+	if d := s.document(); d != nil {
+		cfg["settings"] = d
+	}
+	return cfg
+}
@@ -0,0 +1,7 @@
+package booga
+
+// User is OS user that services run as.
+type User struct {
+	UID uint32
+	GID uint32
+}
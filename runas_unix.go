@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package booga
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// setCredential makes cmd process run as user.
+func setCredential(cmd *exec.Cmd, u *User) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{Uid: u.UID, Gid: u.GID}
+	return nil
+}
+
+// chown changes owner of path to user.
+func chown(path string, u *User) error {
+	return os.Lchown(path, int(u.UID), int(u.GID))
+}
+
+// chownAll changes owner of dir and everything in it to user.
+func chownAll(dir string, u *User) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return chown(path, u)
+	})
+}
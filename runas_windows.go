@@ -0,0 +1,19 @@
+package booga
+
+import (
+	"os/exec"
+
+	"golang.org/x/xerrors"
+)
+
+func setCredential(cmd *exec.Cmd, u *User) error {
+	return xerrors.New("running as user is not supported on windows")
+}
+
+func chown(path string, u *User) error {
+	return xerrors.New("running as user is not supported on windows")
+}
+
+func chownAll(dir string, u *User) error {
+	return xerrors.New("running as user is not supported on windows")
+}
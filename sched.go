@@ -0,0 +1,39 @@
+package booga
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Scheduling configures CPU scheduling of service processes, so large
+// cluster can be kept away from cores running tests.
+type Scheduling struct {
+	// Nice is niceness from -20 (highest priority) to 19, requires nice.
+	// Negative values require privileges.
+	Nice int
+	// CPUs restricts process to CPU cores by index, requires taskset on
+	// linux.
+	CPUs []int
+}
+
+// command returns command that runs binary with args under scheduling
+// constraints, using nice and taskset wrappers that exec binary, so pid
+// of process is preserved.
+func (s Scheduling) command(binary string, args []string) (string, []string) {
+	var wrapper []string
+	if s.Nice != 0 {
+		wrapper = append(wrapper, "nice", "-n", strconv.Itoa(s.Nice))
+	}
+	if len(s.CPUs) > 0 {
+		cpus := make([]string, len(s.CPUs))
+		for i, cpu := range s.CPUs {
+			cpus[i] = strconv.Itoa(cpu)
+		}
+		wrapper = append(wrapper, "taskset", "-c", strings.Join(cpus, ","))
+	}
+	if len(wrapper) == 0 {
+		return binary, args
+	}
+	wrapped := append(wrapper[1:], binary)
+	return wrapper[0], append(wrapped, args...)
+}
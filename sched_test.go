@@ -0,0 +1,24 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchedulingCommand(t *testing.T) {
+	name, args := Scheduling{}.command("mongod", []string{"--port", "1"})
+	if name != "mongod" || !reflect.DeepEqual(args, []string{"--port", "1"}) {
+		t.Errorf("unexpected command %s %v", name, args)
+	}
+
+	name, args = Scheduling{Nice: 10, CPUs: []int{2, 3}}.command("mongod", []string{"--port", "1"})
+	expected := []string{"-n", "10", "taskset", "-c", "2,3", "mongod", "--port", "1"}
+	if name != "nice" || !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected command %s %v", name, args)
+	}
+
+	name, args = Scheduling{CPUs: []int{0}}.command("mongos", nil)
+	if name != "taskset" || !reflect.DeepEqual(args, []string{"-c", "0", "mongos"}) {
+		t.Errorf("unexpected command %s %v", name, args)
+	}
+}
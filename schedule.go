@@ -0,0 +1,166 @@
+package booga
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// ScheduledFault is fault injected to service at given time.
+type ScheduledFault struct {
+	At     time.Duration // since start of schedule
+	Fault  Fault
+	Target string // service name
+}
+
+type scheduledFaultJSON struct {
+	At     string `json:"at"` // e.g. "5s"
+	Fault  Fault  `json:"fault"`
+	Target string `json:"target"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f ScheduledFault) MarshalJSON() ([]byte, error) {
+	return json.Marshal(scheduledFaultJSON{
+		At:     f.At.String(),
+		Fault:  f.Fault,
+		Target: f.Target,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *ScheduledFault) UnmarshalJSON(data []byte) error {
+	var v scheduledFaultJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	at, err := time.ParseDuration(v.At)
+	if err != nil {
+		return xerrors.Errorf("at: %w", err)
+	}
+	*f = ScheduledFault{At: at, Fault: v.Fault, Target: v.Target}
+	return nil
+}
+
+// Schedule is explicit timeline of faults, e.g.
+//
+//	[
+//	  {"at": "5s", "fault": "kill", "target": "data-0-0"},
+//	  {"at": "12s", "fault": "recover", "target": "data-0-0"}
+//	]
+//
+// Faults are not recovered until FaultRecover for the same target. Faults
+// named by ChaosOptions.Nemeses have no target and are recovered by
+// FaultRecover with nemesis name as target.
+type Schedule []ScheduledFault
+
+// LoadSchedule reads JSON schedule from file.
+func LoadSchedule(name string) (Schedule, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, xerrors.Errorf("read: %w", err)
+	}
+	var s Schedule
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, xerrors.Errorf("unmarshal: %w", err)
+	}
+	return s, nil
+}
+
+// Schedule returns successfully injected and recovered faults as schedule,
+// so Chaos run can be replayed by RunSchedule.
+func (ch *Chaos) Schedule() Schedule {
+	var s Schedule
+	for _, a := range ch.Actions() {
+		if a.Err != nil {
+			continue
+		}
+		s = append(s, ScheduledFault{At: a.At, Fault: a.Fault, Target: a.Target})
+	}
+	return s
+}
+
+// scheduledNemesis returns nemesis of scheduled fault.
+func (ch *Chaos) scheduledNemesis(f ScheduledFault) (Nemesis, error) {
+	if n, ok := ch.opt.Nemeses[string(f.Fault)]; ok {
+		return n, nil
+	}
+	for _, s := range ch.cluster.Services() {
+		if s.Name == f.Target {
+			return &faultNemesis{fault: f.Fault, target: s}, nil
+		}
+	}
+	return nil, xerrors.Errorf("no service %s", f.Target)
+}
+
+// RunSchedule injects faults of schedule at their times. Faults that are
+// not recovered by schedule are recovered before RunSchedule returns.
+func (ch *Chaos) RunSchedule(ctx context.Context, schedule Schedule) error {
+	schedule = append(Schedule(nil), schedule...)
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].At < schedule[j].At
+	})
+
+	log := ch.cluster.log.Named("chaos")
+	active := map[string][]Nemesis{}
+	recoverTarget := func(ctx context.Context, target string) error {
+		for _, n := range active[target] {
+			if err := n.Recover(ctx, ch.cluster); err != nil {
+				return err
+			}
+		}
+		delete(active, target)
+		return nil
+	}
+	defer func() {
+		// Recovering even if context is canceled, so cluster is not left
+		// broken.
+		recoverCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		for target := range active {
+			if err := recoverTarget(recoverCtx, target); err != nil {
+				log.Warn("Failed to recover", zap.String("target", target), zap.Error(err))
+			}
+		}
+	}()
+
+	start := time.Now()
+	for _, f := range schedule {
+		if !sleep(ctx, f.At-time.Since(start)) {
+			return nil
+		}
+		log.Info("Injecting scheduled fault",
+			zap.Duration("at", f.At),
+			zap.String("fault", string(f.Fault)),
+			zap.String("target", f.Target),
+		)
+
+		if f.Fault == FaultRecover {
+			err := recoverTarget(ctx, f.Target)
+			ch.record(f.At, f.Fault, f.Target, err)
+			if err != nil {
+				return xerrors.Errorf("recover %s: %w", f.Target, err)
+			}
+			continue
+		}
+
+		n, err := ch.scheduledNemesis(f)
+		if err != nil {
+			return err
+		}
+		err = n.Inject(ctx, ch.cluster)
+		ch.record(f.At, f.Fault, f.Target, err)
+		if err != nil {
+			return xerrors.Errorf("inject %s to %s: %w", f.Fault, f.Target, err)
+		}
+		key := recoveryKey(f.Fault, f.Target)
+		active[key] = append(active[key], n)
+	}
+
+	return nil
+}
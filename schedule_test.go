@@ -0,0 +1,42 @@
+package booga
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduleJSON(t *testing.T) {
+	const data = `[
+		{"at": "5s", "fault": "partition", "target": "data-0-0"},
+		{"at": "12.5s", "fault": "recover", "target": "data-0-0"}
+	]`
+	var s Schedule
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatal(err)
+	}
+	expected := Schedule{
+		{At: time.Second * 5, Fault: FaultPartition, Target: "data-0-0"},
+		{At: time.Millisecond * 12500, Fault: FaultRecover, Target: "data-0-0"},
+	}
+	if !reflect.DeepEqual(s, expected) {
+		t.Fatalf("got %+v", s)
+	}
+
+	out, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Schedule
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("round trip: %+v", decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`[{"at": "soon"}]`), &s); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+}
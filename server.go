@@ -9,56 +9,268 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/xerrors"
 )
 
 type Cluster struct {
-	log *zap.Logger
-
-	mongod string // mongod binary path
-	mongos string // mongos binary path
+	configErr error // result of Config.Validate, returned by Run
 
-	dir string // base directory
-	db  string // database name
-
-	replicas int
-	shards   int
+	log *zap.Logger
+	// logLevel filters logs of services, see SetLogLevel.
+	logLevel zap.AtomicLevel
+	// nodeLoggers and typeLoggers override logger of services.
+	nodeLoggers map[string]*zap.Logger
+	typeLoggers map[ServerType]*zap.Logger
+	// scheduling is CPU scheduling of services by server type.
+	scheduling map[ServerType]Scheduling
+	// runAs is user that services run as.
+	runAs *User
+	// warnings are startup warnings of services, failOnWarnings are
+	// patterns of fatal ones.
+	warnings       startupWarnings
+	failOnWarnings []string
+	// logExporter ships service logs queued to logQueue.
+	logExporter LogExporter
+	logQueue    chan LogRecord
+	// logRedaction is applied to service logs.
+	logRedaction *logRedactor
+
+	mongod  string // mongod binary path
+	mongos  string // mongos binary path
+	mongosh string // mongosh binary path
+	// binaryDir is the only directory searched for missing binaries.
+	binaryDir string
+	// download is version of downloaded binaries, see binaryDownloader.
+	download       string
+	downloadTarget string
+	downloadDir    string
+	offline        bool
+	// coreDumpDir is directory of collected core files.
+	coreDumpDir string
+	// testCommands enables test commands like configureFailPoint.
+	testCommands bool
+	ferretDB     string // ferretdb binary path
+	// partitions places services into own cgroups, see cgroups.
+	partitions bool
+	cgroups    *serviceCgroups
+	// fileLimit is open files limit of services, zero if not managed.
+	fileLimit uint64
+	// auth is cluster user if access control is enabled, keyFile is
+	// absolute path of key file of members.
+	auth    *Credentials
+	keyFile string
+
+	mongodSHA256    string
+	mongosSHA256    string
+	inspectBinaries bool
+	version         string // expected version of binaries
+
+	dir      string // base directory
+	db       string // database name
+	useTmpfs bool
+	// diskSizes limits data directory size by node name.
+	diskSizes map[string]int64
+
+	replicas       int
+	shards         int
+	configReplicas int
 
 	maxCacheGB float64
+	shardSpecs []ShardSpec
+	naming     Naming
+	router     RouterOptions
+
+	collections       []Collection
+	clusterParameters map[string]interface{}
+	majorityConcerns  bool
+	chunkSizeMB       int
+	noAutoSplit       bool
+	noAutoMerge       bool
+	gridFS            []GridFSBucket
 
 	onSetup      func(ctx context.Context, client *mongo.Client) error
+	onReady      func(ctx context.Context, client *mongo.Client) error
 	setupTimeout time.Duration
-	services     map[string]func()
+	startRetries int
+	services     serviceRegistry
+
+	// serve runs services until cluster stops, see addMember.
+	serve    *errgroup.Group
+	serveCtx context.Context
+	// graph orders startup and shutdown of services.
+	graph dependencyGraph
+	// mirrorReads is mirrorReads sampling rate of shard members.
+	mirrorReads float64
+	// ttlMonitor is interval between passes of TTL monitor.
+	ttlMonitor time.Duration
+	// txnLifetime and txnLockTimeout are transaction limits of shard
+	// members.
+	txnLifetime    time.Duration
+	txnLockTimeout time.Duration
+	// noFlowControl and flowControlLag configure flow control of shard
+	// members.
+	noFlowControl  bool
+	flowControlLag time.Duration
+	// memberTags are replica set tags of shard members by node name.
+	memberTags map[string]map[string]string
+	// replicaSetSettings are settings overrides by replica set name.
+	replicaSetSettings map[string]ReplicaSetSettings
+	// members are replica ids of shard members if changed at runtime.
+	members    map[int][]int
+	nextMember map[int]int
+	membersMux sync.Mutex
+	logs       logHub
+
+	usageInterval   time.Duration
+	statsInterval   time.Duration
+	statsNamespaces []string
+	oplogArchiveDir string
+	adminAddr       string
+	probeAddr       string
+	// sidecars are auxiliary services started after cluster is ready.
+	sidecars []Sidecar
+
+	coordinate bool
+	portOffset int // added to every port
+
+	tracer      trace.Tracer
+	timeline    timeline
+	startupDone func(err error) // ends startup phase
+
+	cache      *Cache
+	cacheEntry string // cache entry directory
+	restored   bool   // state is restored from cache
+
+	ready     chan struct{} // closed when cluster is ready
+	readyOnce sync.Once
+	lifecycle lifecycle
+
+	parentDeathSignal bool
+	detached          bool // services should outlive current process
+
+	retention Retention
+	closeOnce sync.Once
+	closeErr  error
 }
 
 func New(opt Config) *Cluster {
+	specs := opt.shardSpecs()
+	if opt.FerretDB != "" {
+		// FerretDB has no shards.
+		specs = nil
+		opt.Cache = nil
+	}
+	var logQueue chan LogRecord
+	if opt.LogExporter != nil {
+		logQueue = make(chan LogRecord, 4*exportBatch)
+	}
 	return &Cluster{
-		log: opt.Log,
-
-		mongod:     opt.Mongod,
-		mongos:     opt.Mongos,
-		dir:        opt.Dir,
-		db:         "cloud",
-		replicas:   opt.Replicas,
-		shards:     opt.Shards,
-		maxCacheGB: opt.MaxCacheGB,
+		configErr: opt.Validate(),
+
+		log:      opt.Log,
+		logLevel: zap.NewAtomicLevelAt(zap.InfoLevel),
+
+		nodeLoggers: opt.NodeLoggers,
+		typeLoggers: opt.TypeLoggers,
+		scheduling:  opt.Scheduling,
+		runAs:       opt.RunAs,
+		logExporter: opt.LogExporter,
+		logQueue:    logQueue,
+
+		logRedaction: opt.LogRedaction.redactor(),
+
+		failOnWarnings: opt.FailOnStartupWarnings,
+
+		mongod:    opt.Mongod,
+		mongos:    opt.Mongos,
+		mongosh:   opt.Mongosh,
+		binaryDir: opt.BinaryDir,
+
+		download:       opt.Download,
+		downloadTarget: opt.DownloadTarget,
+		downloadDir:    opt.downloadDir(),
+		offline:        opt.Offline,
+
+		coreDumpDir:  opt.CoreDumpDir,
+		testCommands: opt.EnableTestCommands,
+		partitions:   opt.EnablePartitions,
+		ferretDB:     opt.FerretDB,
+		auth:         opt.credentials(),
+
+		mongodSHA256:    opt.MongodSHA256,
+		mongosSHA256:    opt.MongosSHA256,
+		inspectBinaries: opt.InspectBinaries || opt.MongodSHA256 != "" || opt.MongosSHA256 != "" || opt.Version != "",
+		version:         opt.Version,
+
+		dir:         opt.Dir,
+		useTmpfs:    opt.UseTmpfs,
+		diskSizes:   opt.DiskSizes,
+		memberTags:  opt.MemberTags,
+		mirrorReads: opt.MirrorReadsSamplingRate,
+		ttlMonitor:  opt.TTLMonitorInterval,
+		db:          opt.db(),
+		replicas:    opt.Replicas,
+		shards:      len(specs),
+		maxCacheGB:  opt.MaxCacheGB,
+		shardSpecs:  specs,
+		naming:      opt.Naming.withDefaults(),
+		router:      opt.Router,
+
+		replicaSetSettings: opt.ReplicaSetSettings,
+		configReplicas:     opt.configReplicas(),
+
+		txnLifetime:    opt.TransactionLifetimeLimit,
+		txnLockTimeout: opt.TransactionLockRequestTimeout,
+		noFlowControl:  opt.DisableFlowControl,
+		flowControlLag: opt.FlowControlTargetLag,
+
+		collections:       opt.Collections,
+		clusterParameters: opt.ClusterParameters,
+		majorityConcerns:  opt.MajorityConcerns,
+		chunkSizeMB:       opt.ChunkSizeMB,
+		noAutoSplit:       opt.DisableAutoSplit,
+		noAutoMerge:       opt.DisableAutoMerge,
+		gridFS:            opt.GridFS,
 
 		setupTimeout: opt.SetupTimeout,
+		startRetries: opt.StartRetries,
 		onSetup:      opt.OnSetup,
+		onReady:      opt.OnReady,
+
+		usageInterval:   opt.UsageInterval,
+		statsInterval:   opt.StatsInterval,
+		statsNamespaces: opt.StatsNamespaces,
+		oplogArchiveDir: opt.OplogArchiveDir,
+		adminAddr:       opt.AdminAddr,
+		probeAddr:       opt.ProbeAddr,
 
-		services: map[string]func(){},
+		sidecars: opt.Sidecars,
+
+		coordinate: opt.Coordinate,
+
+		tracer: newTracer(opt.TracerProvider),
+
+		cache: opt.Cache,
+
+		ready:     make(chan struct{}),
+		retention: opt.Retention,
+
+		parentDeathSignal: opt.ParentDeathSignal,
 	}
 }
 
@@ -80,100 +292,370 @@ func ensureTempDir(name string) (context.CancelFunc, error) {
 	}, nil
 }
 
-type serverType byte
+// ServerType is type of mongo server.
+type ServerType byte
 
 const (
-	// dataServer is just regular mongo instance.
-	dataServer serverType = iota
-	// configServer is topology configuration mongo instance.
-	configServer
-	// routingServer is router (proxy) for queries, mongos.
-	routingServer
+	// DataServer is just regular mongo instance.
+	DataServer ServerType = iota
+	// ConfigServer is topology configuration mongo instance.
+	ConfigServer
+	// RoutingServer is router (proxy) for queries, mongos.
+	RoutingServer
+	// FerretDBServer is FerretDB proxy with embedded SQLite storage, used
+	// instead of the whole cluster, see Config.FerretDB.
+	FerretDBServer
+	// SidecarServer is auxiliary process, see Sidecar.
+	SidecarServer
 )
 
+func (t ServerType) String() string {
+	switch t {
+	case DataServer:
+		return "data"
+	case ConfigServer:
+		return "config"
+	case RoutingServer:
+		return "routing"
+	case FerretDBServer:
+		return "ferretdb"
+	case SidecarServer:
+		return "sidecar"
+	default:
+		return "unknown"
+	}
+}
+
 // serverOptions for running mongo.
 type serverOptions struct {
-	Type       serverType
+	Type       ServerType
 	BinaryPath string
 	Name       string
 
-	ReplicaSet string // only for configServer or dataServer
-	BaseDir    string // only for configServer or dataServer
+	ReplicaSet string  // only for ConfigServer or DataServer
+	BaseDir    string  // only for ConfigServer or DataServer
+	MaxCacheGB float64 // only for ConfigServer or DataServer
 
-	ConfigServerAddr string // only for routingServer
+	ConfigServerAddr string // only for RoutingServer
+
+	ShardID   int // only for DataServer
+	ReplicaID int // only for ConfigServer or DataServer
 
 	OnReady func(ctx context.Context, client *mongo.Client) error
 
+	Args []string // extra arguments
 	IP   string
 	Port int
 }
 
+// dataServerOptions returns options of shard replica set member.
+func (c *Cluster) dataServerOptions(shardID, id int) serverOptions {
+	spec := c.shardSpecs[shardID]
+	args := mirrorReadsArgs(c.mirrorReads)
+	args = append(args, ttlMonitorArgs(c.ttlMonitor)...)
+	args = append(args, txnLimitsArgs(c.txnLifetime, c.txnLockTimeout)...)
+	args = append(args, flowControlArgs(c.noFlowControl, c.flowControlLag)...)
+	args = append(args, spec.Args...)
+	return serverOptions{
+		Name:       c.dataName(shardID, id),
+		BaseDir:    c.dir,
+		BinaryPath: spec.Mongod,
+		MaxCacheGB: spec.MaxCacheGB,
+		Args:       args,
+		ReplicaSet: c.shardReplicaSet(shardID),
+		Type:       DataServer,
+		ShardID:    shardID,
+		ReplicaID:  id,
+
+		IP:   "127.0.0.1",
+		Port: c.dataPort(shardID, id),
+	}
+}
+
+// startRetryDelay is delay between launch attempts of service.
+const startRetryDelay = time.Millisecond * 500
+
+// resetNode cleans up data directory of node after failed launch,
+// restoring cached state if any.
+func (c *Cluster) resetNode(opt serverOptions, dir string) error {
+	switch opt.Type {
+	case DataServer, ConfigServer:
+	default:
+		return nil
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return xerrors.Errorf("remove: %w", err)
+	}
+	if c.restored {
+		return c.restoreNode(opt.Name)
+	}
+
+	return ensureDir(dir)
+}
+
+// startWithRetries runs launch until process exits after service was
+// ready or start attempts are exhausted. Data of node is reset between
+// attempts of initial start only: restarted node rejoins with its data,
+// so it is never wiped.
+func (c *Cluster) startWithRetries(ctx context.Context, log *zap.Logger, restart bool, launch func(ctx context.Context, restart bool) (bool, error), reset func() error) error {
+	for attempt := 1; ; attempt++ {
+		ready, err := launch(ctx, restart)
+		if err == nil || ready || ctx.Err() != nil || attempt > c.startRetries {
+			return err
+		}
+
+		// Process failed before service became ready, e.g. because of
+		// stale lock file or port race.
+		log.Warn("Service failed to start, retrying",
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+		if !restart {
+			if err := reset(); err != nil {
+				return xerrors.Errorf("reset: %w", err)
+			}
+		}
+		if !sleep(ctx, startRetryDelay) {
+			return ctx.Err()
+		}
+	}
+}
+
+// serviceLogger returns logger of service.
+func (c *Cluster) serviceLogger(opt serverOptions) *zap.Logger {
+	if log, ok := c.nodeLoggers[opt.Name]; ok {
+		return log.Named(opt.Name)
+	}
+	if log, ok := c.typeLoggers[opt.Type]; ok {
+		return log.Named(opt.Name)
+	}
+	return c.log.Named(opt.Name)
+}
+
 // runServer runs mongo server with provided options until error or context
 // cancellation.
 func (c *Cluster) runServer(ctx context.Context, opt serverOptions) error {
-	log := c.log.Named(opt.Name)
+	log := c.serviceLogger(opt)
+	defer func() {
+		// Retired service is deregistered after its directory is
+		// cleaned up.
+		retired := false
+		c.services.with(opt.Name, func(s *service) { retired = s.retired })
+		if retired {
+			_ = c.services.remove(opt.Name)
+		}
+	}()
 
 	dir := filepath.Join(opt.BaseDir, opt.Name)
 	switch opt.Type {
-	case dataServer, configServer:
+	case DataServer, ConfigServer, FerretDBServer:
 		// Ensuring instance directory.
 		log.Info("State will be persisted to tmp directory", zap.String("dir", dir))
 		cleanup, err := ensureTempDir(dir)
 		if err != nil {
 			return xerrors.Errorf("ensure dir: %w", err)
 		}
-		// Directory will be removed recursively on cleanup.
-		defer cleanup()
+		if c.retention == RetainNone {
+			// Directory will be removed recursively on cleanup.
+			defer cleanup()
+		}
+		if size, ok := c.diskSizes[opt.Name]; ok {
+			unmount, err := mountTmpfs(dir, size)
+			if err != nil {
+				return xerrors.Errorf("mount %d bytes: %w", size, err)
+			}
+			log.Info("Data directory size is limited", zap.Int64("bytes", size))
+			defer func() {
+				if err := unmount(); err != nil {
+					log.Warn("Failed to unmount data directory", zap.Error(err))
+				}
+			}()
+			if c.restored {
+				// Cached state is copied to mounted tmpfs, otherwise it
+				// would be hidden by mount.
+				if err := c.restoreNode(opt.Name); err != nil {
+					return xerrors.Errorf("restore: %w", err)
+				}
+			}
+		}
 	}
 
+	ctx, serverDone := c.phase(ctx, "Server", opt.Name, serverAttributes(opt)...)
 	g, gCtx := errgroup.WithContext(ctx)
 
 	log.Info("Starting")
+	if err := c.registerService(opt); err != nil {
+		serverDone(err)
+		return err
+	}
 	g.Go(func() error {
 		// Piping mongo logs to zap logger.
-		logReader, logFlush := logProxy(log, g)
+		logReader, logFlush := logProxy(withLevel(log, c.logLevel), g, c.logRedaction, func(e Entry) {
+			c.logs.publish(opt.Name, e)
+			c.queueLog(opt.Name, e)
+		})
 		defer logFlush()
 
-		args := []string{
-			"--bind_ip", opt.IP,
-			"--port", strconv.Itoa(opt.Port),
+		var args []string
+		switch opt.Type {
+		case FerretDBServer:
+			args = ferretDBArgs(opt)
+		default:
+			args = []string{
+				"--bind_ip", opt.IP,
+				"--port", strconv.Itoa(opt.Port),
+			}
 		}
 
 		switch opt.Type {
-		case configServer:
+		case ConfigServer:
 			args = append(args, "--configsvr")
-		case dataServer:
+		case DataServer:
 			args = append(args, "--shardsvr")
 		}
 
 		switch opt.Type {
-		case configServer, dataServer:
+		case ConfigServer, DataServer:
 			args = append(args,
 				"--replSet", opt.ReplicaSet,
 				"--dbpath", ".",
 			)
-			if c.maxCacheGB > 0 {
-				args = append(args, "--wiredTigerCacheSizeGB", fmt.Sprintf("%f", c.maxCacheGB))
+			if opt.MaxCacheGB > 0 {
+				args = append(args, "--wiredTigerCacheSizeGB", fmt.Sprintf("%f", opt.MaxCacheGB))
 			}
-		case routingServer:
+		case RoutingServer:
 			// Routing server is stateless.
 			args = append(args, "--configdb", opt.ConfigServerAddr)
 		}
+		if c.testCommands && opt.Type != FerretDBServer {
+			args = append(args, testCommandsArgs()...)
+		}
+		if c.keyFile != "" && opt.Type != FerretDBServer {
+			args = append(args, "--keyFile", c.keyFile)
+		}
+		args = append(args, opt.Args...)
 
-		return c.runRegistered(gCtx, opt.Name, func(ctx context.Context) error {
-			cmd := exec.CommandContext(ctx, opt.BinaryPath, args...)
-			cmd.Stdout = logReader
-			cmd.Stderr = logReader
+		if c.detached && opt.Type != FerretDBServer {
+			// Process should outlive current one, so logs can't be piped.
+			logPath, err := filepath.Abs(filepath.Join(c.dir, opt.Name+".log"))
+			if err != nil {
+				return xerrors.Errorf("log path: %w", err)
+			}
+			args = append(args, "--logpath", logPath, "--logappend")
+			log.Info("Logging to file", zap.String("path", logPath))
+		}
+
+		// launch runs process until exit and reports whether service was
+		// ready before exit.
+		launch := func(ctx context.Context, restart bool) (bool, error) {
+			binary, binaryArgs := c.scheduling[opt.Type].command(opt.BinaryPath, args)
+			if c.cgroups != nil {
+				var err error
+				binary, binaryArgs, err = c.cgroups.command(opt.Name, binary, binaryArgs)
+				if err != nil {
+					return false, xerrors.Errorf("cgroup: %w", err)
+				}
+			}
+			cmd := exec.Command(binary, binaryArgs...)
+			if !c.detached {
+				cmd.Stdout = logReader
+				cmd.Stderr = logReader
+			}
 
 			switch opt.Type {
-			case configServer, dataServer:
+			case ConfigServer, DataServer, FerretDBServer:
 				cmd.Dir = dir
+			default:
+				if c.coreDumpDir != "" {
+					// Core files are written to working directory.
+					cmd.Dir = c.dir
+				}
+			}
+
+			if c.runAs != nil {
+				// Base directory holds logs of detached services and
+				// core files of routers.
+				if err := chown(opt.BaseDir, c.runAs); err != nil {
+					return false, xerrors.Errorf("chown: %w", err)
+				}
+				if opt.Type != RoutingServer {
+					// Files can be created by current user, e.g. on
+					// restore from cache.
+					if err := chownAll(dir, c.runAs); err != nil {
+						return false, xerrors.Errorf("chown: %w", err)
+					}
+				}
+				if err := setCredential(cmd, c.runAs); err != nil {
+					return false, err
+				}
+			}
+
+			// Process is started in separate process group that is killed
+			// as a whole, so no orphaned processes are left.
+			setProcessGroup(cmd)
+			if c.parentDeathSignal && !c.detached {
+				setParentDeathSignal(cmd)
+			}
+
+			// Core file size limit is raised for services only, so
+			// current process doesn't dump core.
+			if err := startWithLimits(cmd, c.fileLimit, c.coreDumpDir != ""); err != nil {
+				return false, err
+			}
+			c.setProcess(opt.Name, cmd.Process)
+			if restart {
+				// Readiness of first run is checked by OnReady routine.
+				go c.awaitReady(ctx, opt.Name)
+			}
+			trace.SpanFromContext(ctx).AddEvent("Process started", trace.WithAttributes(
+				attribute.Int("booga.pid", cmd.Process.Pid),
+			))
+
+			exited := make(chan struct{})
+			go func() {
+				select {
+				case <-ctx.Done():
+					_ = signalGroup(cmd.Process, syscall.SIGKILL)
+				case <-exited:
+				}
+			}()
+
+			err := cmd.Wait()
+			close(exited)
+			ready := c.serviceState(opt.Name) == ServiceReady
+			code := -1
+			if cmd.ProcessState != nil {
+				code = cmd.ProcessState.ExitCode()
+			}
+			err = exitError(opt.Name, code, err)
+			if c.coreDumpDir != "" && ctx.Err() == nil && cmd.ProcessState != nil && crashed(cmd.ProcessState) {
+				if err := c.collectCores(ctx, opt, cmd.Dir); err != nil {
+					log.Warn("Failed to collect core files", zap.Error(err))
+				}
+			}
+			c.setExited(opt.Name, code, err)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ready, ctxErr
 			}
 
-			return cmd.Run()
+			return ready, err
+		}
+
+		restarted := false
+		return c.runRegistered(gCtx, opt.Name, func(ctx context.Context) error {
+			restart := restarted
+			restarted = true
+
+			return c.startWithRetries(ctx, log, restart, launch, func() error {
+				return c.resetNode(opt, dir)
+			})
 		})
 	})
-	g.Go(func() error {
+	g.Go(func() (err error) {
+		// Server span ends when server is ready.
+		defer func() { serverDone(err) }()
+
 		uri := &url.URL{
 			Scheme: "mongodb",
 			Host:   net.JoinHostPort(opt.IP, strconv.Itoa(opt.Port)),
@@ -197,9 +679,18 @@ func (c *Cluster) runServer(ctx context.Context, opt serverOptions) error {
 		ensureCtx, cancel := context.WithTimeout(gCtx, c.setupTimeout)
 		defer cancel()
 
-		if err := ensureServer(ensureCtx, log, client); err != nil {
+		pingCtx, pingDone := c.phase(ensureCtx, "Ping", opt.Name)
+		err = ensureServer(pingCtx, log, client)
+		pingDone(err)
+		if err != nil {
 			return xerrors.Errorf("ensure server: %w", err)
 		}
+		if opt.Type != FerretDBServer {
+			if err := c.checkStartupWarnings(ensureCtx, opt.Name, client); err != nil {
+				return xerrors.Errorf("startup warnings: %w", err)
+			}
+		}
+		c.setReady(opt.Name)
 
 		if err := opt.OnReady(gCtx, client); err != nil {
 			return xerrors.Errorf("onReady: %w", err)
@@ -208,198 +699,523 @@ func (c *Cluster) runServer(ctx context.Context, opt serverOptions) error {
 		return nil
 	})
 
-	return g.Wait()
+	err := g.Wait()
+	// Process could be never started, so service is marked as stopped
+	// explicitly.
+	c.services.with(opt.Name, func(s *service) {
+		c.services.transition(s, ServiceStopped)
+	})
+
+	return err
 }
 
 type Config struct {
 	Log *zap.Logger
-
-	Mongod string // mongod binary path
-	Mongos string // mongos binary path
+	// NodeLoggers and TypeLoggers override Log for services by node name
+	// or by server type, node name taking precedence. Use zap.NewNop to
+	// silence services.
+	NodeLoggers map[string]*zap.Logger
+	TypeLoggers map[ServerType]*zap.Logger
+	// Scheduling sets niceness and CPU affinity of services by server
+	// type.
+	Scheduling map[ServerType]Scheduling
+	// RunAs runs services as given user, e.g. when tests run as root,
+	// changing owner of data directories. Dir must be accessible by user.
+	// Not supported on windows.
+	RunAs *User
+	// FailOnStartupWarnings fails startup if startup warning of service
+	// contains any of substrings, e.g. "vm.max_map_count" or
+	// "transparent_hugepage", see StartupWarnings.
+	FailOnStartupWarnings []string
+	// LogExporter ships parsed logs of services to external collector,
+	// e.g. LokiExporter or HTTPExporter.
+	LogExporter LogExporter
+	// LogRedaction redacts sensitive attributes of service logs.
+	LogRedaction *LogRedaction
+
+	// Mongod and Mongos are binary paths. If empty, binaries are looked up
+	// in BinaryDir if set, otherwise in MongoHomeEnv, PATH and common
+	// install locations.
+	Mongod string
+	Mongos string
+	// BinaryDir is directory with pre-provisioned binaries, e.g. for
+	// hermetic CI. If set, binaries are looked up only in BinaryDir, so
+	// missing binary fails startup immediately.
+	BinaryDir string
+	// Download is full version of MongoDB Community Server, e.g. "7.0.14",
+	// that is downloaded to DownloadDir and used instead of lookup.
+	// DownloadTarget is build target of linux distribution, e.g.
+	// "ubuntu2204", detected from /etc/os-release by default.
+	Download       string
+	DownloadTarget string
+	// DownloadDir is cache directory of downloaded binaries, "booga" in
+	// user cache directory by default.
+	DownloadDir string
+	// Offline fails startup with clear error instead of downloading
+	// binaries that are missing from DownloadDir.
+	Offline bool
+	// Mongosh is mongosh binary path for Shell, "mongosh" by default.
+	Mongosh string
+	// FerretDB is ferretdb binary path. If set, single FerretDB service
+	// with SQLite storage is started instead of sharded cluster and
+	// Replicas, Shards, Cache and sharding options are ignored.
+	FerretDB string
+	// MongodSHA256 and MongosSHA256 are expected hex-encoded checksums of
+	// Mongod and Mongos binaries, verified before startup.
+	MongodSHA256 string
+	MongosSHA256 string
+	// InspectBinaries records path, version and checksum of every binary
+	// to StartupReport, implied by checksums and Version.
+	InspectBinaries bool
+	// Version is expected version of binaries or its prefix, e.g. "7.0",
+	// verified before startup.
+	Version string
 
 	Dir string // base directory
-	DB  string // database name
+	DB  string // database name, "cloud" by default
+	// MirrorReadsSamplingRate is fraction of reads that primaries mirror
+	// to secondaries, see SetMirrorReads. Requires 4.4.
+	MirrorReadsSamplingRate float64
+	// TTLMonitorInterval is interval between passes of TTL monitor of
+	// shard members, at least one second, 60s by default. See
+	// SetTTLMonitorInterval and RunTTLPass.
+	TTLMonitorInterval time.Duration
+	// TransactionLifetimeLimit aborts transactions running longer, at
+	// least one second, 60s by default. TransactionLockRequestTimeout is
+	// how long transaction waits for locks before abort, 5ms by default.
+	// Aggressive limits make transaction timeouts quick to reproduce.
+	TransactionLifetimeLimit      time.Duration
+	TransactionLockRequestTimeout time.Duration
+	// DisableFlowControl disables throttling of writes when majority
+	// commit point lags, see SetFlowControl. FlowControlTargetLag is lag
+	// that flow control keeps, at least one second, 10s by default.
+	DisableFlowControl   bool
+	FlowControlTargetLag time.Duration
+	// MemberTags are replica set tags of shard members by node name, e.g.
+	// {"data-0-1": {"dc": "east"}}, for read preference tag sets.
+	MemberTags map[string]map[string]string
+	// ReplicaSetSettings overrides replica set settings by replica set
+	// name, e.g. shorter election timeout for faster failovers. Entry
+	// with empty name applies to replica sets without own entry.
+	ReplicaSetSettings map[string]ReplicaSetSettings
+	// UseTmpfs places data directories on tmpfs, see RamDiskEnv.
+	UseTmpfs bool
+	// DiskSizes limits size of data directory in bytes by node name, so
+	// node runs out of disk space when limit is reached. Directory is
+	// placed on size-limited tmpfs, that requires CAP_SYS_ADMIN on linux.
+	DiskSizes map[string]int64
 
 	Replicas int
 	Shards   int
+	// ConfigReplicas is count of members of config server replica set, 1
+	// by default.
+	ConfigReplicas int
+	// ShardSpecs configures heterogeneous shards, overriding Shards.
+	ShardSpecs []ShardSpec
 
 	MaxCacheGB float64
 
-	OnSetup      func(ctx context.Context, client *mongo.Client) error
+	// Router tunes every routing server.
+	Router RouterOptions
+
+	// Naming of replica sets, shards and services.
+	Naming Naming
+
+	// Auth enables access control: members authenticate each other with
+	// generated key file and every connection string of cluster has
+	// credentials of cluster user with root role. Not supported with
+	// FerretDB.
+	Auth *Credentials
+
+	// MajorityConcerns sets cluster-wide default read and write concerns
+	// to majority before OnSetup. Requires 4.4.
+	MajorityConcerns bool
+	// ChunkSizeMB sets default chunk size of cluster before OnSetup, 128MB
+	// by default since 6.0, see SetChunkSize.
+	ChunkSizeMB int
+	// DisableAutoSplit and DisableAutoMerge disable automatic splitting
+	// and merging of chunks before OnSetup, so chunk boundaries change only
+	// on explicit requests. See SetAutoSplit and SetAutoMerge.
+	DisableAutoSplit bool
+	DisableAutoMerge bool
+	// ClusterParameters to set before OnSetup, see SetClusterParameter.
+	ClusterParameters map[string]interface{}
+	// Collections to create before OnSetup.
+	Collections []Collection
+	// GridFS buckets to seed before OnSetup.
+	GridFS []GridFSBucket
+
+	OnSetup func(ctx context.Context, client *mongo.Client) error
+	// OnReady is called after cluster is set up or restored from Cache.
+	OnReady      func(ctx context.Context, client *mongo.Client) error
 	SetupTimeout time.Duration
+	// StartRetries is count of extra launch attempts of service that
+	// exits before it is ready, e.g. because of transient port conflict.
+	// Data directory is cleaned up between attempts.
+	StartRetries int
+
+	// Cache of seeded state, optional.
+	Cache *Cache
+
+	// Coordinate enables cross-process registry that reserves
+	// non-overlapping port range and subdirectory of Dir, so multiple
+	// processes can run clusters at the same time.
+	Coordinate bool
+
+	// UsageInterval enables periodic logging of ResourceUsage.
+	UsageInterval time.Duration
+	// StatsInterval enables periodic logging of dbStats of databases and
+	// collStats of collections listed in StatsNamespaces, e.g. "app" or
+	// "app.orders", cluster database by default.
+	StatsInterval   time.Duration
+	StatsNamespaces []string
+
+	// OplogArchiveDir enables archiving of oplog of every shard to
+	// given directory after cluster is ready, see ArchiveOplog.
+	OplogArchiveDir string
+
+	// AdminAddr enables HTTP admin endpoint and web dashboard on given
+	// address, see Handler.
+	AdminAddr string
+	// ProbeAddr enables HTTP health probes on given address, see
+	// ProbeHandler.
+	ProbeAddr string
+
+	// Sidecars are auxiliary processes started when their dependencies
+	// are up, after cluster is ready by default, see RegisterService.
+	Sidecars []Sidecar
+
+	// TracerProvider for startup tracing, global provider by default.
+	TracerProvider trace.TracerProvider
+
+	// Retention of data directories, RetainNone by default.
+	Retention Retention
+
+	// CoreDumpDir enables core dumps of services: core file size limit of
+	// services is raised and core files of crashed service are moved to
+	// subdirectory of CoreDumpDir with binary description instead of being
+	// removed with data directory. Requires kernel core pattern that
+	// writes core to working directory, e.g. "core". Not supported on
+	// windows.
+	CoreDumpDir string
+	// EnableTestCommands enables test commands of mongod and mongos, that
+	// are required for failpoints, see SetFailPoint.
+	EnableTestCommands bool
+	// EnablePartitions runs every service in own cgroup, so FaultPartition
+	// and Partitioner drop all traffic of service, including connections
+	// that service opens to others. Requires linux with cgroup v2,
+	// iptables and root, not supported with RunAs.
+	EnablePartitions bool
+
+	// ParentDeathSignal makes OS kill services if current process dies,
+	// Linux only.
+	ParentDeathSignal bool
 }
 
-func dataPort(shardID, id int) int {
-	return 29000 + shardID*100 + id
+// ensure runs services of cluster by dependency graph: config replica set
+// is initialized first, then shards, routing server and sidecars.
+func (c *Cluster) ensure(ctx context.Context) error {
+	g, gCtx := errgroup.WithContext(ctx)
+	// Members and sidecars added at runtime are run in the same group.
+	c.serve, c.serveCtx = g, gCtx
+
+	if err := c.buildGraph(); err != nil {
+		return xerrors.Errorf("dependencies: %w", err)
+	}
+	if err := c.graph.startAll(gCtx, g); err != nil {
+		return xerrors.Errorf("dependencies: %w", err)
+	}
+
+	return g.Wait()
 }
 
-func (c *Cluster) ensure(ctx context.Context) error {
+// buildGraph adds nodes of cluster and configured sidecars to dependency
+// graph.
+func (c *Cluster) buildGraph() error {
+	if c.ferretDB != "" {
+		if err := c.graph.add(c.routingName(), nil, c.ensureFerretDB); err != nil {
+			return err
+		}
+	} else {
+		if err := c.graph.add(c.configReplicaSet(), nil, c.runConfig); err != nil {
+			return err
+		}
+		shards := make([]string, 0, c.shards)
+		for shardID := 0; shardID < c.shards; shardID++ {
+			shardID := shardID
+			rsName := c.shardReplicaSet(shardID)
+			if err := c.graph.add(rsName, []string{c.configReplicaSet()}, func(ctx context.Context) error {
+				return c.runShard(ctx, shardID)
+			}); err != nil {
+				return err
+			}
+			shards = append(shards, rsName)
+		}
+		if err := c.graph.add(c.routingName(), shards, c.runRouter); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range c.sidecars {
+		if err := c.addSidecar(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// graphNodeOf returns dependency graph node of service.
+func (c *Cluster) graphNodeOf(s ServiceInfo) string {
+	switch s.Type {
+	case ConfigServer:
+		return c.configReplicaSet()
+	case DataServer:
+		return c.shardReplicaSet(s.ShardID)
+	default:
+		return s.Name
+	}
+}
+
+// runConfig runs config servers and initializes their replica set.
+func (c *Cluster) runConfig(ctx context.Context) error {
 	g, gCtx := errgroup.WithContext(ctx)
-	replicaSetInitialized := make(chan struct{})
+	rsName := c.configReplicaSet()
 
-	const (
-		rsData   = "rsData"
-		rsConfig = "rsConfig"
-	)
+	var members []bson.M
+	for id := 0; id < c.configReplicas; id++ {
+		members = append(members, bson.M{"_id": id, "host": localAddr(c.configPort(id))})
+	}
+	rsConfig := c.replicaSetConfig(rsName, members)
 
-	// Configuration servers.
-	g.Go(func() error {
-		return c.runServer(gCtx, serverOptions{
-			Name:       "cfg",
+	var initOnce sync.Once
+
+	for id := 0; id < c.configReplicas; id++ {
+		opt := serverOptions{
+			Name:       c.configMemberName(id),
 			BaseDir:    c.dir,
 			BinaryPath: c.mongod,
-			ReplicaSet: rsConfig,
-			Type:       configServer,
+			MaxCacheGB: c.maxCacheGB,
+			ReplicaSet: rsName,
+			Type:       ConfigServer,
+			ShardID:    -1,
+			ReplicaID:  id,
 			OnReady: func(ctx context.Context, client *mongo.Client) error {
-				// Initializing config replica set.
-				rsConfig := bson.M{
-					"_id": rsConfig,
-					"members": []bson.M{
-						{"_id": 0, "host": "127.0.0.1:28001"},
-					},
+				if c.restored {
+					// Replica set configuration is restored from cache.
+					c.graph.markUp(rsName)
+					return nil
 				}
-				if err := client.Database("admin").
-					RunCommand(ctx, bson.M{"replSetInitiate": rsConfig}).
-					Err(); err != nil {
+
+				var err error
+				initOnce.Do(func() {
+					ctx, done := c.phase(ctx, "replSetInitiate", rsName)
+					err = client.Database("admin").
+						RunCommand(ctx, bson.M{"replSetInitiate": rsConfig}).
+						Err()
+					done(err)
+					if err == nil {
+						c.log.Info("Config replica set initialized")
+					}
+				})
+				if err != nil {
 					return xerrors.Errorf("replSetInitiate: %w", err)
 				}
-
-				c.log.Info("Config replica set initialized")
-				close(replicaSetInitialized)
+				c.graph.markUp(rsName)
 
 				return nil
 			},
 
 			IP:   "127.0.0.1",
-			Port: 28001,
+			Port: c.configPort(id),
+		}
+
+		g.Go(func() error {
+			return c.runServer(gCtx, opt)
 		})
-	})
+	}
 
-	// Data servers.
-	g.Go(func() error {
-		select {
-		case <-replicaSetInitialized:
-		case <-gCtx.Done():
-			return gCtx.Err()
-		}
+	return g.Wait()
+}
 
-		dG, dCtx := errgroup.WithContext(gCtx)
+// runShard runs members of shard and initializes its replica set.
+func (c *Cluster) runShard(ctx context.Context, shardID int) error {
+	g, gCtx := errgroup.WithContext(ctx)
+	rsName := c.shardReplicaSet(shardID)
 
-		for shardID := 0; shardID < c.shards; shardID++ {
-			rsName := fmt.Sprintf("%s%d", rsData, shardID)
+	var members []bson.M
+	for _, id := range c.shardMembers(shardID) {
+		members = append(members, c.memberConfig(shardID, id))
+	}
+	rsConfig := c.replicaSetConfig(rsName, members)
 
-			var members []bson.M
-			for id := 0; id < c.replicas; id++ {
-				members = append(members, bson.M{
-					"_id":  id,
-					"host": fmt.Sprintf("127.0.0.1:%d", dataPort(shardID, id)),
-				})
-			}
-			rsConfig := bson.M{
-				"_id":     rsName,
-				"members": members,
+	var initOnce sync.Once
+
+	for id := 0; id < c.shardReplicas(shardID); id++ {
+		opt := c.dataServerOptions(shardID, id)
+		opt.OnReady = func(ctx context.Context, client *mongo.Client) error {
+			if c.restored {
+				c.graph.markUp(rsName)
+				return nil
 			}
 
-			var initOnce sync.Once
-
-			for id := 0; id < c.replicas; id++ {
-				opt := serverOptions{
-					Name:       fmt.Sprintf("data-%d-%d", shardID, id),
-					BaseDir:    c.dir,
-					BinaryPath: c.mongod,
-					ReplicaSet: rsName,
-					Type:       dataServer,
-
-					OnReady: func(ctx context.Context, client *mongo.Client) error {
-						var err error
-						initOnce.Do(func() {
-							err = client.Database("admin").
-								RunCommand(ctx, bson.M{"replSetInitiate": rsConfig}).
-								Err()
-						})
-						if err != nil {
-							return xerrors.Errorf("init: %w", err)
-						}
-
-						return nil
-					},
-
-					IP:   "127.0.0.1",
-					Port: dataPort(shardID, id),
+			var err error
+			initOnce.Do(func() {
+				ctx, done := c.phase(ctx, "replSetInitiate", rsName)
+				err = client.Database("admin").
+					RunCommand(ctx, bson.M{"replSetInitiate": rsConfig}).
+					Err()
+				done(err)
+				if err == nil && c.auth != nil {
+					err = c.createShardUser(ctx, shardID)
 				}
-
-				dG.Go(func() error {
-					return c.runServer(dCtx, opt)
-				})
+			})
+			if err != nil {
+				return xerrors.Errorf("init: %w", err)
 			}
-		}
-
-		return dG.Wait()
-	})
+			c.graph.markUp(rsName)
 
-	// Routing or "mongos" servers.
-	g.Go(func() error {
-		select {
-		case <-replicaSetInitialized:
-		case <-gCtx.Done():
-			return gCtx.Err()
+			return nil
 		}
 
-		return c.runServer(gCtx, serverOptions{
-			Name:             "routing",
-			BinaryPath:       c.mongos,
-			Type:             routingServer,
-			ConfigServerAddr: path.Join(rsConfig, "127.0.0.1:28001"),
+		g.Go(func() error {
+			return c.runServer(gCtx, opt)
+		})
+	}
 
-			OnReady: func(ctx context.Context, client *mongo.Client) error {
-				// Add every shard.
-				for shardID := 0; shardID < c.shards; shardID++ {
-					// Specify every replica set member.
-					rsName := fmt.Sprintf("%s%d", rsData, shardID)
-					var rsAddr []string
-					for id := 0; id < c.replicas; id++ {
-						rsAddr = append(rsAddr, fmt.Sprintf("127.0.0.1:%d", dataPort(shardID, id)))
-					}
-					if err := client.Database("admin").
-						RunCommand(ctx, bson.M{
-							"addShard": path.Join(rsName, strings.Join(rsAddr, ",")),
-						}).
-						Err(); err != nil {
-						return xerrors.Errorf("addShard: %w", err)
+	return g.Wait()
+}
+
+// runRouter runs routing server and initializes cluster.
+func (c *Cluster) runRouter(ctx context.Context) error {
+	return c.runServer(ctx, serverOptions{
+		Name:             c.routingName(),
+		BinaryPath:       c.mongos,
+		Type:             RoutingServer,
+		ShardID:          -1,
+		ReplicaID:        -1,
+		ConfigServerAddr: path.Join(c.configReplicaSet(), strings.Join(c.configAddrs(), ",")),
+		Args:             c.router.args(),
+
+		OnReady: func(ctx context.Context, client *mongo.Client) error {
+			if c.auth != nil {
+				if !c.restored {
+					// No users exist yet, so localhost exception applies.
+					if err := c.createUser(ctx, client); err != nil {
+						return xerrors.Errorf("create user: %w", err)
 					}
 				}
-
-				c.log.Info("Shards added")
-
-				c.log.Info("Initializing database")
-				// Mongo does not provide explicit way to create database.
-				// Just creating void collection.
-				if err := client.Database(c.db).CreateCollection(ctx, "_init"); err != nil {
-					return xerrors.Errorf("create collection: %w", err)
+				authClient, err := connect(ctx, c.routerURI())
+				if err != nil {
+					return err
 				}
+				defer func() { _ = authClient.Disconnect(ctx) }()
+				client = authClient
+			}
 
-				c.log.Info("Enabling sharding")
-				if err := client.Database("admin").
-					RunCommand(ctx, bson.M{"enableSharding": c.db}).
-					Err(); err != nil {
-					return xerrors.Errorf("enableSharding: %w", err)
+			if c.restored {
+				c.log.Info("State restored from cache, skipping initialization")
+			} else {
+				if err := c.initialize(ctx, client); err != nil {
+					return err
 				}
+				if err := c.saveCache(ctx); err != nil {
+					return xerrors.Errorf("save cache: %w", err)
+				}
+			}
 
-				c.log.Info("Sharding enabled", zap.String("db", c.db))
-
-				if err := c.setup(ctx, client); err != nil {
-					return xerrors.Errorf("OnSetup: %w", err)
+			if c.onReady != nil {
+				if err := c.onReady(ctx, client); err != nil {
+					return xerrors.Errorf("OnReady: %w", err)
 				}
+			}
+			c.startupDone(nil)
+			c.markReady()
+			c.graph.markUp(c.routingName())
 
-				return nil
-			},
+			return nil
+		},
 
-			IP:   "127.0.0.1",
-			Port: 29501,
-		})
+		IP:   "127.0.0.1",
+		Port: c.routingPort(),
 	})
+}
 
-	return g.Wait()
+// initialize adds shards to cluster, enables sharding and seeds data.
+func (c *Cluster) initialize(ctx context.Context, client *mongo.Client) error {
+	// Add every shard.
+	for shardID := 0; shardID < c.shards; shardID++ {
+		// Specify every replica set member.
+		rsName := c.shardReplicaSet(shardID)
+		rsAddr := c.shardAddrs(shardID)
+		phaseCtx, done := c.phase(ctx, "addShard", rsName)
+		err := client.Database("admin").
+			RunCommand(phaseCtx, bson.M{
+				"addShard": path.Join(rsName, strings.Join(rsAddr, ",")),
+			}).
+			Err()
+		done(err)
+		if err != nil {
+			return xerrors.Errorf("addShard: %w", err)
+		}
+	}
+
+	c.log.Info("Shards added")
+
+	c.log.Info("Initializing database")
+	// Mongo does not provide explicit way to create database.
+	// Just creating void collection.
+	if err := client.Database(c.db).CreateCollection(ctx, "_init"); err != nil {
+		return xerrors.Errorf("create collection: %w", err)
+	}
+
+	c.log.Info("Enabling sharding")
+	if err := client.Database("admin").
+		RunCommand(ctx, bson.M{"enableSharding": c.db}).
+		Err(); err != nil {
+		return xerrors.Errorf("enableSharding: %w", err)
+	}
+
+	c.log.Info("Sharding enabled", zap.String("db", c.db))
+
+	if c.majorityConcerns {
+		if err := client.Database("admin").RunCommand(ctx, bson.M{
+			"setDefaultRWConcern": 1,
+			"defaultReadConcern":  bson.M{"level": "majority"},
+			"defaultWriteConcern": bson.M{"w": "majority"},
+		}).Err(); err != nil {
+			return xerrors.Errorf("setDefaultRWConcern: %w", err)
+		}
+	}
+	if c.chunkSizeMB > 0 {
+		if err := setChunkSize(ctx, client, c.chunkSizeMB); err != nil {
+			return xerrors.Errorf("chunk size: %w", err)
+		}
+	}
+	if c.noAutoSplit {
+		if err := setSetting(ctx, client, "autosplit", bson.M{"enabled": false}); err != nil {
+			return xerrors.Errorf("autosplit: %w", err)
+		}
+	}
+	if c.noAutoMerge {
+		if err := setSetting(ctx, client, "automerge", bson.M{"enabled": false}); err != nil {
+			return xerrors.Errorf("automerge: %w", err)
+		}
+	}
+	if err := c.setupClusterParameters(ctx, client); err != nil {
+		return xerrors.Errorf("cluster parameters: %w", err)
+	}
+	if err := c.setupCollections(ctx, client); err != nil {
+		return xerrors.Errorf("collections: %w", err)
+	}
+	if err := c.setupGridFS(ctx, client); err != nil {
+		return xerrors.Errorf("gridfs: %w", err)
+	}
+
+	if err := c.setup(ctx, client); err != nil {
+		return xerrors.Errorf("OnSetup: %w", err)
+	}
+
+	return nil
 }
 
 // ensureServer ensures that mongo server is up on given uri.
@@ -437,44 +1253,172 @@ func (c *Cluster) setup(ctx context.Context, client *mongo.Client) error {
 	if c.onSetup == nil {
 		return nil
 	}
-	return c.onSetup(ctx, client)
+
+	ctx, done := c.phase(ctx, "OnSetup", "")
+	err := c.onSetup(ctx, client)
+	done(err)
+
+	return err
 }
 
-func (c *Cluster) Run(ctx context.Context) error {
-	return c.ensure(ctx)
+// background runs f in goroutine and returns function that cancels
+// context of f and waits for f to return.
+func background(ctx context.Context, f func(ctx context.Context)) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(ctx)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
 }
 
-func (c *Cluster) runRegistered(parentCtx context.Context, name string, f func(ctx context.Context) error) error {
-	ctx, cancel := context.WithCancel(parentCtx)
+func (c *Cluster) Run(ctx context.Context) error {
+	defer func() {
+		if r := recover(); r != nil {
+			c.kill()
+			panic(r)
+		}
+	}()
 
-	g, gCtx := errgroup.WithContext(ctx)
-	g.Go(func() error {
-		return f(gCtx)
-	})
+	if c.configErr != nil {
+		return c.configErr
+	}
+	if err := c.ensureFileLimit(); err != nil {
+		return xerrors.Errorf("file limit: %w", err)
+	}
 
-	c.services[name] = cancel
+	if c.coordinate {
+		s, err := acquireSlot()
+		if err != nil {
+			return xerrors.Errorf("acquire slot: %w", err)
+		}
+		defer func() {
+			if err := s.Release(); err != nil {
+				c.log.Warn("Failed to release slot", zap.Error(err))
+			}
+		}()
 
-	return g.Wait()
-}
+		c.portOffset = s.PortOffset()
+		c.dir = filepath.Join(c.dir, s.Dir())
+		c.log.Info("Slot acquired",
+			zap.Int("slot", s.ID),
+			zap.String("dir", c.dir),
+		)
+	}
 
-func (c *Cluster) Services() []string {
-	var services []string
+	if c.partitions {
+		cgroups, err := newServiceCgroups()
+		if err != nil {
+			return xerrors.Errorf("partitions: %w", err)
+		}
+		c.cgroups = cgroups
+		if !c.detached {
+			// Cgroups of detached services are kept while they run.
+			defer func() {
+				if err := cgroups.close(); err != nil {
+					c.log.Warn("Failed to remove cgroups", zap.Error(err))
+				}
+			}()
+		}
+	}
 
-	for k := range c.services {
-		services = append(services, k)
+	if c.coreDumpDir != "" {
+		if err := checkCorePattern(corePatternPath); err != nil {
+			c.log.Warn("Core files of services won't be collected", zap.Error(err))
+		}
 	}
 
-	sort.Strings(services)
-	return services
-}
+	if c.useTmpfs {
+		release, err := c.placeOnTmpfs()
+		if err != nil {
+			return xerrors.Errorf("tmpfs: %w", err)
+		}
+		if !c.detached {
+			// Detached services keep using directory.
+			defer release()
+		}
+	}
+
+	if c.auth != nil {
+		keyFile, err := c.writeKeyFile()
+		if err != nil {
+			return xerrors.Errorf("key file: %w", err)
+		}
+		c.keyFile = keyFile
+	}
+
+	ctx, c.startupDone = c.phase(ctx, "Startup", "")
+	defer c.startupDone(nil)
 
-func (c *Cluster) Kill(name string) error {
-	f, ok := c.services[name]
-	if !ok {
-		return xerrors.Errorf("no service %s", name)
+	if c.ferretDB == "" {
+		if err := c.discoverBinaries(ctx); err != nil {
+			err = xerrors.Errorf("discover binaries: %w", err)
+			c.startupDone(err)
+			return err
+		}
+	}
+	if c.inspectBinaries {
+		verifyCtx, done := c.phase(ctx, "Verify binaries", "")
+		err := c.verifyBinaries(verifyCtx)
+		done(err)
+		if err != nil {
+			err = xerrors.Errorf("verify binaries: %w", err)
+			c.startupDone(err)
+			return err
+		}
 	}
 
-	f()
+	if err := c.restoreCache(ctx); err != nil {
+		err = xerrors.Errorf("restore cache: %w", err)
+		c.startupDone(err)
+		return err
+	}
+
+	if c.usageInterval > 0 {
+		defer background(ctx, c.logUsage)()
+	}
+	if c.statsInterval > 0 {
+		defer background(ctx, c.logStats)()
+	}
+	if c.logExporter != nil {
+		defer background(ctx, c.exportLogs)()
+	}
+	if c.adminAddr != "" {
+		defer background(ctx, func(ctx context.Context) {
+			if err := c.serveAdmin(ctx); err != nil {
+				c.log.Warn("Admin endpoint failed", zap.Error(err))
+			}
+		})()
+	}
+	if c.probeAddr != "" {
+		defer background(ctx, func(ctx context.Context) {
+			if err := c.serveHTTP(ctx, "probe", c.probeAddr, c.ProbeHandler()); err != nil {
+				c.log.Warn("Probe endpoint failed", zap.Error(err))
+			}
+		})()
+	}
+	if c.oplogArchiveDir != "" {
+		defer background(ctx, c.archiveOplog)()
+	}
+
+	if err := c.ensure(ctx); err != nil {
+		// No-op if cluster was started successfully.
+		c.startupDone(err)
+
+		// Cluster can be partially started, so ensuring that nothing
+		// is left behind.
+		if reapErr := c.reap(); reapErr != nil {
+			c.log.Error("Failed to clean up", zap.Error(reapErr))
+			return multierr.Append(err, xerrors.Errorf("cleanup: %w", reapErr))
+		}
+
+		return err
+	}
 
 	return nil
 }
@@ -0,0 +1,38 @@
+package booga
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+func TestStartWithRetries(t *testing.T) {
+	c := &Cluster{startRetries: 2}
+	failed := xerrors.New("failed")
+	for _, restart := range []bool{false, true} {
+		var launches, resets int
+		err := c.startWithRetries(context.Background(), zap.NewNop(), restart, func(ctx context.Context, r bool) (bool, error) {
+			launches++
+			return false, failed
+		}, func() error {
+			resets++
+			return nil
+		})
+		if !xerrors.Is(err, failed) {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if launches != 3 {
+			t.Errorf("restart %v: got %d launches", restart, launches)
+		}
+		wantResets := 2
+		if restart {
+			// Data of restarted node is kept.
+			wantResets = 0
+		}
+		if resets != wantResets {
+			t.Errorf("restart %v: got %d resets, want %d", restart, resets, wantResets)
+		}
+	}
+}
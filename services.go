@@ -0,0 +1,502 @@
+package booga
+
+import (
+	"context"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// ServiceState is lifecycle state of service.
+type ServiceState byte
+
+const (
+	// ServiceStarting means that service process is starting or not
+	// responding yet.
+	ServiceStarting ServiceState = iota
+	// ServiceReady means that service responded to ping.
+	ServiceReady
+	// ServiceStopped means that service process exited.
+	ServiceStopped
+)
+
+func (s ServiceState) String() string {
+	switch s {
+	case ServiceStarting:
+		return "starting"
+	case ServiceReady:
+		return "ready"
+	case ServiceStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// ServiceInfo describes cluster service.
+type ServiceInfo struct {
+	Name string
+	Type ServerType
+
+	ShardID   int // -1 if not DataServer
+	ReplicaID int // -1 for RoutingServer
+
+	Addr    string // host:port
+	PID     int    // zero if process is not started
+	State   ServiceState
+	Started time.Time // process start time
+	// ExitCode is exit code of last process run if service is stopped,
+	// -1 if process was terminated by signal or code is unknown.
+	ExitCode int
+}
+
+// service is registered cluster service.
+type service struct {
+	info    ServiceInfo
+	cancel  context.CancelFunc
+	process *os.Process // nil if not running
+	exitErr error       // result of last process run
+
+	killed     bool          // process is killed by Kill
+	terminated bool          // process is terminated by Stop
+	retired    bool          // service is stopped permanently by retire
+	restart    chan struct{} // signals killed service to restart
+	// finished means that service exited without Kill, so it can't be
+	// restarted.
+	finished bool
+}
+
+// validTransition reports whether service can change state from one to
+// another. Service becomes ready only while starting, but can be
+// (re)started or stopped in any state.
+func validTransition(from, to ServiceState) bool {
+	if to == ServiceReady {
+		return from == ServiceStarting
+	}
+	return true
+}
+
+// serviceRegistry is concurrency-safe set of cluster services that tracks
+// their state transitions. Zero value is ready to use.
+type serviceRegistry struct {
+	mux      sync.Mutex
+	services map[string]*service
+	changed  chan struct{} // closed on every state transition
+}
+
+// add registers service, replacing stopped service with the same name.
+func (r *serviceRegistry) add(s *service) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.services == nil {
+		r.services = map[string]*service{}
+	}
+	if prev, ok := r.services[s.info.Name]; ok && prev.info.State != ServiceStopped {
+		return xerrors.Errorf("service %s is already registered", s.info.Name)
+	}
+	r.services[s.info.Name] = s
+	r.notify()
+
+	return nil
+}
+
+// remove deregisters stopped service.
+func (r *serviceRegistry) remove(name string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	s, ok := r.services[name]
+	if !ok {
+		return xerrors.Errorf("no service %s", name)
+	}
+	if s.info.State != ServiceStopped {
+		return xerrors.Errorf("service %s is %s", name, s.info.State)
+	}
+	delete(r.services, name)
+	r.notify()
+
+	return nil
+}
+
+// with calls f on registered service while holding lock and reports
+// whether service is registered.
+func (r *serviceRegistry) with(name string, f func(s *service)) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	s, ok := r.services[name]
+	if ok {
+		f(s)
+	}
+	return ok
+}
+
+// each calls f on every registered service while holding lock.
+func (r *serviceRegistry) each(f func(s *service)) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for _, s := range r.services {
+		f(s)
+	}
+}
+
+// transition changes state of service if transition is valid and
+// reports whether state was changed. Lock must be held.
+func (r *serviceRegistry) transition(s *service, state ServiceState) bool {
+	if !validTransition(s.info.State, state) {
+		return false
+	}
+	if s.info.State != state {
+		s.info.State = state
+		r.notify()
+	}
+	return true
+}
+
+// notify wakes up waiters of state change. Lock must be held.
+func (r *serviceRegistry) notify() {
+	if r.changed != nil {
+		close(r.changed)
+		r.changed = nil
+	}
+}
+
+// state returns state of service and channel that is closed on next
+// change.
+func (r *serviceRegistry) state(name string) (ServiceState, bool, <-chan struct{}) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.changed == nil {
+		r.changed = make(chan struct{})
+	}
+	s, ok := r.services[name]
+	if !ok {
+		return ServiceStopped, false, r.changed
+	}
+	return s.info.State, true, r.changed
+}
+
+// waitFor blocks until f returns true or error for state of service.
+func (r *serviceRegistry) waitFor(ctx context.Context, name string, f func(state ServiceState, registered bool) (bool, error)) error {
+	for {
+		state, ok, changed := r.state(name)
+		done, err := f(state, ok)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// wait blocks until service reaches state.
+func (r *serviceRegistry) wait(ctx context.Context, name string, state ServiceState) error {
+	if err := r.waitFor(ctx, name, func(current ServiceState, ok bool) (bool, error) {
+		if !ok {
+			return false, xerrors.Errorf("no service %s", name)
+		}
+		return current == state, nil
+	}); err != nil {
+		return xerrors.Errorf("wait %s %s: %w", name, state, err)
+	}
+	return nil
+}
+
+// list returns info of every service, sorted by name.
+func (r *serviceRegistry) list() []ServiceInfo {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	services := make([]ServiceInfo, 0, len(r.services))
+	for _, s := range r.services {
+		services = append(services, s.info)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	return services
+}
+
+// registerService registers service described by opt as starting.
+func (c *Cluster) registerService(opt serverOptions) error {
+	return c.services.add(&service{
+		info: ServiceInfo{
+			Name:      opt.Name,
+			Type:      opt.Type,
+			ShardID:   opt.ShardID,
+			ReplicaID: opt.ReplicaID,
+			Addr:      net.JoinHostPort(opt.IP, strconv.Itoa(opt.Port)),
+			State:     ServiceStarting,
+		},
+	})
+}
+
+// setReady marks starting service as ready.
+func (c *Cluster) setReady(name string) {
+	c.services.with(name, func(s *service) {
+		c.services.transition(s, ServiceReady)
+	})
+}
+
+// setProcess sets running process of service.
+func (c *Cluster) setProcess(name string, p *os.Process) {
+	c.services.with(name, func(s *service) {
+		s.process = p
+		s.exitErr = nil
+		s.info.PID = p.Pid
+		s.info.Started = time.Now()
+		c.services.transition(s, ServiceStarting)
+	})
+}
+
+// serviceState returns state of service.
+func (c *Cluster) serviceState(name string) ServiceState {
+	state, _, _ := c.services.state(name)
+	return state
+}
+
+// setExited marks service process as exited with code and err.
+func (c *Cluster) setExited(name string, code int, err error) {
+	c.services.with(name, func(s *service) {
+		s.process = nil
+		s.exitErr = err
+		s.info.ExitCode = code
+		c.services.transition(s, ServiceStopped)
+	})
+}
+
+// runRegistered runs f until it returns. If service is killed by Kill,
+// f is called again after Restart.
+func (c *Cluster) runRegistered(parentCtx context.Context, name string, f func(ctx context.Context) error) error {
+	restart := make(chan struct{}, 1)
+	c.services.with(name, func(s *service) {
+		s.restart = restart
+	})
+
+	for {
+		ctx, cancel := context.WithCancel(parentCtx)
+		killed := false
+		c.services.with(name, func(s *service) {
+			s.cancel = cancel
+			s.killed = false
+		})
+
+		err := f(ctx)
+		cancel()
+
+		retired := false
+		c.services.with(name, func(s *service) {
+			killed, retired = s.killed, s.retired
+		})
+		if retired {
+			return nil
+		}
+		if !killed || parentCtx.Err() != nil {
+			c.services.with(name, func(s *service) {
+				s.finished = true
+			})
+			return err
+		}
+
+		c.log.Info("Service killed, waiting for restart", zap.String("name", name))
+		select {
+		case <-restart:
+		case <-parentCtx.Done():
+			return parentCtx.Err()
+		}
+		c.services.with(name, func(s *service) {
+			retired = s.retired
+		})
+		if retired {
+			return nil
+		}
+		c.log.Info("Restarting service", zap.String("name", name))
+	}
+}
+
+// awaitReady marks restarted service as ready when it responds to ping.
+func (c *Cluster) awaitReady(ctx context.Context, name string) {
+	// Ping requires no authentication, and users can be not created yet.
+	u, err := c.serviceURL(name)
+	if err != nil {
+		return
+	}
+	client, err := connect(ctx, u.String())
+	if err != nil {
+		return
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	ensureCtx, cancel := context.WithTimeout(ctx, c.setupTimeout)
+	defer cancel()
+	if err := ensureServer(ensureCtx, c.log.Named(name), client); err != nil {
+		c.log.Warn("Restarted service is not ready", zap.String("name", name), zap.Error(err))
+		return
+	}
+
+	c.setReady(name)
+}
+
+// Services returns description of every service, sorted by name.
+func (c *Cluster) Services() []ServiceInfo {
+	return c.services.list()
+}
+
+func (c *Cluster) hasService(name string) bool {
+	return c.services.with(name, func(s *service) {})
+}
+
+// Deregister removes stopped service from cluster, so it is no longer
+// listed by Services.
+func (c *Cluster) Deregister(name string) error {
+	return c.services.remove(name)
+}
+
+// Process returns underlying process of running service.
+//
+// Process can be used to send custom signals or to inspect process state,
+// but should not be waited on or released.
+func (c *Cluster) Process(name string) (*os.Process, error) {
+	var p *os.Process
+	if !c.services.with(name, func(s *service) { p = s.process }) {
+		return nil, xerrors.Errorf("no service %s", name)
+	}
+	if p == nil {
+		return nil, xerrors.Errorf("service %s is not running", name)
+	}
+
+	return p, nil
+}
+
+// PID returns process id of running service.
+func (c *Cluster) PID(name string) (int, error) {
+	p, err := c.Process(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.Pid, nil
+}
+
+// Kill kills service process. Killed service can be started again by
+// Restart, reusing its data directory, so node rejoins replica set with
+// its data instead of initial sync.
+func (c *Cluster) Kill(name string) error {
+	var cancel context.CancelFunc
+	c.services.with(name, func(s *service) {
+		cancel = s.cancel
+		s.killed = true
+	})
+	if cancel == nil {
+		return xerrors.Errorf("no service %s", name)
+	}
+
+	cancel()
+
+	return nil
+}
+
+// retire stops service permanently and waits until it is deregistered.
+func (c *Cluster) retire(ctx context.Context, name string) error {
+	var (
+		cancel  context.CancelFunc
+		restart chan struct{}
+	)
+	if !c.services.with(name, func(s *service) {
+		s.retired = true
+		cancel, restart = s.cancel, s.restart
+	}) {
+		return xerrors.Errorf("no service %s", name)
+	}
+	if cancel != nil {
+		cancel()
+	}
+	if restart != nil {
+		// Waking up killed service that waits for restart.
+		select {
+		case restart <- struct{}{}:
+		default:
+		}
+	}
+
+	return c.services.waitFor(ctx, name, func(_ ServiceState, registered bool) (bool, error) {
+		return !registered, nil
+	})
+}
+
+// KillWait kills service process like Kill and blocks until process
+// exits, so its port and data directory are released. Returns exit code
+// of process, see ServiceInfo.ExitCode.
+func (c *Cluster) KillWait(ctx context.Context, name string) (int, error) {
+	if err := c.Kill(name); err != nil {
+		return 0, err
+	}
+	if err := c.waitServiceState(ctx, name, ServiceStopped); err != nil {
+		return 0, err
+	}
+
+	var code int
+	c.services.with(name, func(s *service) {
+		code = s.info.ExitCode
+	})
+	return code, nil
+}
+
+// Restart restarts service, killing it first if it is running. Service
+// that exited on its own, e.g. crashed, can't be restarted.
+func (c *Cluster) Restart(name string) error {
+	var (
+		running  bool
+		finished bool
+		exitErr  error
+		restart  chan struct{}
+	)
+	c.services.with(name, func(s *service) {
+		running = s.process != nil
+		finished, exitErr = s.finished, s.exitErr
+		restart = s.restart
+	})
+	if restart == nil {
+		return xerrors.Errorf("no service %s", name)
+	}
+	if finished {
+		if exitErr != nil {
+			return xerrors.Errorf("service %s exited without kill and can't be restarted: %w", name, exitErr)
+		}
+		return xerrors.Errorf("service %s exited without kill and can't be restarted", name)
+	}
+
+	if running {
+		if err := c.Kill(name); err != nil {
+			return err
+		}
+	}
+	select {
+	case restart <- struct{}{}:
+	default:
+		// Restart is already pending.
+	}
+
+	return nil
+}
+
+// waitServiceState blocks until service reaches state.
+func (c *Cluster) waitServiceState(ctx context.Context, name string, state ServiceState) error {
+	return c.services.wait(ctx, name, state)
+}
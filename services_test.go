@@ -0,0 +1,124 @@
+package booga
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+func TestServiceRegistry(t *testing.T) {
+	var r serviceRegistry
+	s := &service{info: ServiceInfo{Name: "data-0-0", State: ServiceStarting}}
+	if err := r.add(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.add(&service{info: ServiceInfo{Name: "data-0-0"}}); err == nil {
+		t.Fatal("running service replaced")
+	}
+	if err := r.remove("data-0-0"); err == nil {
+		t.Fatal("running service removed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- r.wait(ctx, "data-0-0", ServiceStopped) }()
+
+	r.with("data-0-0", func(s *service) {
+		if !r.transition(s, ServiceReady) {
+			t.Error("starting service should become ready")
+		}
+		r.transition(s, ServiceStopped)
+		if r.transition(s, ServiceReady) {
+			t.Error("stopped service should not become ready")
+		}
+	})
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.remove("data-0-0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.list(); len(got) != 0 {
+		t.Fatalf("unexpected services %v", got)
+	}
+	if err := r.wait(ctx, "data-0-0", ServiceReady); err == nil {
+		t.Fatal("expected error for removed service")
+	}
+}
+
+func TestRestartKeepsData(t *testing.T) {
+	c := New(Config{Log: zap.NewNop()})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := c.services.add(&service{info: ServiceInfo{Name: "data-0-0", State: ServiceStarting}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Data directory lives while service is registered, as in runServer.
+	dir, err := ioutil.TempDir("", "booga-restart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	marker := filepath.Join(dir, "data")
+
+	runs := make(chan bool, 2) // whether run found data
+	done := make(chan error, 1)
+	go func() {
+		done <- c.runRegistered(ctx, "data-0-0", func(ctx context.Context) error {
+			_, err := os.Stat(marker)
+			runs <- err == nil
+			if err := ioutil.WriteFile(marker, []byte("1"), 0600); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+
+	if <-runs {
+		t.Fatal("data before first run")
+	}
+	if err := c.Kill("data-0-0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Restart("data-0-0"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case found := <-runs:
+		if !found {
+			t.Error("data of restarted service is lost")
+		}
+	case err := <-done:
+		t.Fatalf("service stopped on kill: %v", err)
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+}
+
+func TestRestartExited(t *testing.T) {
+	c := New(Config{Log: zap.NewNop()})
+	if err := c.services.add(&service{info: ServiceInfo{Name: "data-0-0", State: ServiceStarting}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Service exits without kill, e.g. crashes.
+	err := c.runRegistered(context.Background(), "data-0-0", func(ctx context.Context) error {
+		return xerrors.New("crashed")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if err := c.Restart("data-0-0"); err == nil {
+		t.Error("restart of exited service is not reported")
+	}
+}
@@ -0,0 +1,72 @@
+package booga
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"golang.org/x/xerrors"
+)
+
+// CausalToken is a point in cluster causal history.
+//
+// Token obtained from session of one client can be applied to session of
+// another client, so operations in second session will observe every
+// operation that happened before token in first session.
+type CausalToken struct {
+	ClusterTime   bson.Raw
+	OperationTime *primitive.Timestamp
+}
+
+// SessionToken returns current causal token of session.
+func SessionToken(sess mongo.Session) CausalToken {
+	return CausalToken{
+		ClusterTime:   sess.ClusterTime(),
+		OperationTime: sess.OperationTime(),
+	}
+}
+
+// Apply advances session to token.
+func (t CausalToken) Apply(sess mongo.Session) error {
+	if t.ClusterTime != nil {
+		if err := sess.AdvanceClusterTime(t.ClusterTime); err != nil {
+			return xerrors.Errorf("advance cluster time: %w", err)
+		}
+	}
+	if t.OperationTime != nil {
+		if err := sess.AdvanceOperationTime(t.OperationTime); err != nil {
+			return xerrors.Errorf("advance operation time: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// StartCausalSession starts causally consistent session with majority
+// read and write concerns, which are required for read-your-writes
+// guarantees across cluster members.
+//
+// If tokens are provided, session is advanced to each of them.
+func StartCausalSession(client *mongo.Client, tokens ...CausalToken) (mongo.Session, error) {
+	sess, err := client.StartSession(options.Session().
+		SetCausalConsistency(true).
+		SetDefaultReadConcern(readconcern.Majority()).
+		SetDefaultWriteConcern(writeconcern.New(writeconcern.WMajority())),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("start session: %w", err)
+	}
+
+	for _, t := range tokens {
+		if err := t.Apply(sess); err != nil {
+			sess.EndSession(context.Background())
+			return nil, xerrors.Errorf("apply token: %w", err)
+		}
+	}
+
+	return sess, nil
+}
@@ -0,0 +1,64 @@
+package booga
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestCausalSession(t *testing.T) {
+	ctx := context.Background()
+	// Sessions are started without server, client only dials lazily.
+	client, err := mongo.Connect(ctx, options.Client().
+		ApplyURI("mongodb://127.0.0.1:1/").
+		SetServerSelectionTimeout(time.Millisecond*100),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	clusterTime, err := bson.Marshal(bson.M{"$clusterTime": bson.M{
+		"clusterTime": primitive.Timestamp{T: 100, I: 2},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := CausalToken{
+		ClusterTime:   clusterTime,
+		OperationTime: &primitive.Timestamp{T: 100, I: 1},
+	}
+	older := CausalToken{OperationTime: &primitive.Timestamp{T: 50, I: 1}}
+
+	sess, err := StartCausalSession(client, token, older)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sess.EndSession(ctx)
+
+	// Session is advanced to latest token.
+	if got := SessionToken(sess); !reflect.DeepEqual(got, token) {
+		t.Errorf("got token %+v, expected %+v", got, token)
+	}
+
+	cs := sess.(mongo.XSession).ClientSession()
+	if !cs.Consistent {
+		t.Error("session is not causally consistent")
+	}
+	// Default concerns are applied to transactions of session.
+	if err := sess.StartTransaction(); err != nil {
+		t.Fatal(err)
+	}
+	if level := cs.CurrentRc.GetLevel(); level != "majority" {
+		t.Errorf("got read concern %q", level)
+	}
+	if w := cs.CurrentWc.GetW(); w != "majority" {
+		t.Errorf("got write concern %v", w)
+	}
+}
@@ -0,0 +1,122 @@
+package booga
+
+import (
+	"context"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// ShardInfo is shard from config.shards.
+type ShardInfo struct {
+	ID       string   `bson:"_id"`
+	Host     string   `bson:"host"` // replica set name and members
+	State    int      `bson:"state"`
+	Draining bool     `bson:"draining"`
+	Tags     []string `bson:"tags"` // zones
+}
+
+// DatabaseInfo is database from config.databases.
+type DatabaseInfo struct {
+	Name        string `bson:"_id"`
+	Primary     string `bson:"primary"`     // primary shard
+	Partitioned bool   `bson:"partitioned"` // before 6.0
+}
+
+// CollectionInfo is sharded collection with chunk distribution.
+type CollectionInfo struct {
+	Namespace string
+	Key       bson.D
+	Unique    bool
+	Chunks    map[string]int // by shard name
+}
+
+// BalancerState is state of balancer from balancerStatus.
+type BalancerState struct {
+	Mode    string `bson:"mode"` // "full" or "off"
+	Running bool   `bson:"inBalancerRound"`
+}
+
+// Enabled reports whether balancer is enabled.
+func (b BalancerState) Enabled() bool {
+	return b.Mode != "off"
+}
+
+// ShardingStatus is state of sharded cluster, equivalent of sh.status().
+type ShardingStatus struct {
+	Shards      []ShardInfo      // sorted by id
+	Databases   []DatabaseInfo   // sorted by name
+	Collections []CollectionInfo // sorted by namespace
+	Balancer    BalancerState
+}
+
+// ShardingStatus returns state of shards, databases, sharded collections
+// and balancer.
+func (c *Cluster) ShardingStatus(ctx context.Context) (*ShardingStatus, error) {
+	status := &ShardingStatus{}
+	if err := withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		config := client.Database("config")
+
+		cur, err := config.Collection("shards").Find(ctx, bson.M{})
+		if err != nil {
+			return xerrors.Errorf("find shards: %w", err)
+		}
+		if err := cur.All(ctx, &status.Shards); err != nil {
+			return xerrors.Errorf("find shards: %w", err)
+		}
+
+		cur, err = config.Collection("databases").Find(ctx, bson.M{})
+		if err != nil {
+			return xerrors.Errorf("find databases: %w", err)
+		}
+		if err := cur.All(ctx, &status.Databases); err != nil {
+			return xerrors.Errorf("find databases: %w", err)
+		}
+
+		cur, err = config.Collection("collections").Find(ctx, bson.M{})
+		if err != nil {
+			return xerrors.Errorf("find collections: %w", err)
+		}
+		var collections []shardedCollection
+		if err := cur.All(ctx, &collections); err != nil {
+			return xerrors.Errorf("find collections: %w", err)
+		}
+		for _, coll := range collections {
+			if coll.Dropped {
+				continue
+			}
+			chunks, err := chunkCounts(ctx, client, coll)
+			if err != nil {
+				return xerrors.Errorf("%s: %w", coll.ID, err)
+			}
+			status.Collections = append(status.Collections, CollectionInfo{
+				Namespace: coll.ID,
+				Key:       coll.Key,
+				Unique:    coll.Unique,
+				Chunks:    chunks,
+			})
+		}
+
+		if err := client.Database("admin").
+			RunCommand(ctx, bson.M{"balancerStatus": 1}).
+			Decode(&status.Balancer); err != nil {
+			return xerrors.Errorf("balancerStatus: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(status.Shards, func(i, j int) bool {
+		return status.Shards[i].ID < status.Shards[j].ID
+	})
+	sort.Slice(status.Databases, func(i, j int) bool {
+		return status.Databases[i].Name < status.Databases[j].Name
+	})
+	sort.Slice(status.Collections, func(i, j int) bool {
+		return status.Collections[i].Namespace < status.Collections[j].Namespace
+	})
+	return status, nil
+}
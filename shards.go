@@ -0,0 +1,82 @@
+package booga
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Tag of analytics members, following Atlas convention.
+const (
+	AnalyticsTagKey   = "nodeType"
+	AnalyticsTagValue = "ANALYTICS"
+)
+
+// ShardSpec configures single shard, overriding uniform Config values.
+//
+// Zero fields fall back to corresponding Config values.
+type ShardSpec struct {
+	Replicas   int      // count of replica set members
+	MaxCacheGB float64  // WiredTiger cache size of every member
+	Mongod     string   // mongod binary path
+	Args       []string // extra mongod arguments
+	// Analytics adds member for reporting workloads in addition to
+	// Replicas, see AnalyticsClient. Member has zero priority and votes
+	// and is tagged with AnalyticsTagKey. Member is not hidden, because
+	// hidden members can't be selected by read preference.
+	Analytics bool
+}
+
+// members returns count of replica set members including analytics one.
+func (s ShardSpec) members() int {
+	if s.Analytics {
+		return s.Replicas + 1
+	}
+	return s.Replicas
+}
+
+// shardSpecs returns resolved specification of every shard.
+func (opt Config) shardSpecs() []ShardSpec {
+	specs := opt.ShardSpecs
+	if len(specs) == 0 {
+		specs = make([]ShardSpec, opt.Shards)
+	}
+
+	resolved := make([]ShardSpec, 0, len(specs))
+	for _, s := range specs {
+		if s.Replicas == 0 {
+			s.Replicas = opt.Replicas
+		}
+		if s.MaxCacheGB == 0 {
+			s.MaxCacheGB = opt.MaxCacheGB
+		}
+		if s.Mongod == "" {
+			s.Mongod = opt.Mongod
+		}
+		resolved = append(resolved, s)
+	}
+
+	return resolved
+}
+
+// shardReplicas returns initial count of replica set members of shard.
+func (c *Cluster) shardReplicas(shardID int) int {
+	return c.shardSpecs[shardID].members()
+}
+
+// memberConfig returns replica set configuration of initial member.
+func (c *Cluster) memberConfig(shardID, id int) bson.M {
+	m := bson.M{
+		"_id":  id,
+		"host": localAddr(c.dataPort(shardID, id)),
+	}
+	tags := bson.M{}
+	if spec := c.shardSpecs[shardID]; spec.Analytics && id == spec.Replicas {
+		m["priority"] = 0
+		m["votes"] = 0
+		tags[AnalyticsTagKey] = AnalyticsTagValue
+	}
+	for k, v := range c.memberTags[c.dataName(shardID, id)] {
+		tags[k] = v
+	}
+	if len(tags) > 0 {
+		m["tags"] = tags
+	}
+	return m
+}
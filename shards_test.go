@@ -0,0 +1,90 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestConfigShardSpecs(t *testing.T) {
+	opt := Config{
+		Mongod:     "mongod",
+		Shards:     2,
+		Replicas:   3,
+		MaxCacheGB: 0.5,
+	}
+	uniform := ShardSpec{Replicas: 3, MaxCacheGB: 0.5, Mongod: "mongod"}
+	if got := opt.shardSpecs(); !reflect.DeepEqual(got, []ShardSpec{uniform, uniform}) {
+		t.Errorf("uniform: %+v", got)
+	}
+
+	opt.ShardSpecs = []ShardSpec{
+		{},
+		{Replicas: 1, Mongod: "mongod-7.0", Args: []string{"--quiet"}},
+	}
+	expected := []ShardSpec{
+		uniform,
+		{Replicas: 1, MaxCacheGB: 0.5, Mongod: "mongod-7.0", Args: []string{"--quiet"}},
+	}
+	if got := opt.shardSpecs(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("heterogeneous: %+v", got)
+	}
+}
+
+func TestMemberConfig(t *testing.T) {
+	c := New(Config{
+		Shards:     1,
+		ShardSpecs: []ShardSpec{{Replicas: 2, Analytics: true}},
+		MemberTags: map[string]map[string]string{
+			"data-0-1": {"dc": "east"},
+			"data-0-2": {"dc": "west"},
+		},
+	})
+	if got := c.shardMembers(0); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Fatalf("members: %v", got)
+	}
+	if _, ok := c.memberConfig(0, 0)["tags"]; ok {
+		t.Error("member without tags is tagged")
+	}
+	if got := c.memberConfig(0, 1)["tags"]; !reflect.DeepEqual(got, bson.M{"dc": "east"}) {
+		t.Errorf("unexpected tags %v", got)
+	}
+	m := c.memberConfig(0, 2)
+	if m["priority"] != 0 || m["votes"] != 0 {
+		t.Errorf("analytics member is electable: %v", m)
+	}
+	if !reflect.DeepEqual(m["tags"], bson.M{AnalyticsTagKey: AnalyticsTagValue, "dc": "west"}) {
+		t.Errorf("unexpected tags %v", m["tags"])
+	}
+}
+
+func TestReplicaSetConfig(t *testing.T) {
+	chaining := false
+	c := New(Config{
+		Shards: 1,
+		ReplicaSetSettings: map[string]ReplicaSetSettings{
+			"": {ElectionTimeout: time.Second},
+			"rs0": {
+				HeartbeatInterval: 500 * time.Millisecond,
+				ChainingAllowed:   &chaining,
+			},
+		},
+	})
+	members := []bson.M{{"_id": 0}}
+	if got := c.replicaSetConfig("rs0", members)["settings"]; !reflect.DeepEqual(got, bson.M{
+		"heartbeatIntervalMillis": int64(500),
+		"chainingAllowed":         false,
+	}) {
+		t.Errorf("rs0: %v", got)
+	}
+	if got := c.replicaSetConfig("rs1", members)["settings"]; !reflect.DeepEqual(got, bson.M{
+		"electionTimeoutMillis": int64(1000),
+	}) {
+		t.Errorf("rs1: %v", got)
+	}
+	if _, ok := New(Config{}).replicaSetConfig("rs0", members)["settings"]; ok {
+		t.Error("settings without overrides")
+	}
+}
@@ -0,0 +1,82 @@
+package booga
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+func (c *Cluster) shellBinary() string {
+	if c.mongosh == "" {
+		return "mongosh"
+	}
+	return c.mongosh
+}
+
+// serviceURI returns connection string for direct connection to service.
+func (c *Cluster) serviceURI(name string) (string, error) {
+	u, err := c.serviceURL(name)
+	if err != nil {
+		return "", err
+	}
+	u.User = c.userinfo()
+	return u.String(), nil
+}
+
+// serviceURL returns connection string for direct connection to service
+// without credentials.
+func (c *Cluster) serviceURL(name string) (*url.URL, error) {
+	for _, s := range c.Services() {
+		if s.Name != name {
+			continue
+		}
+		if s.Type == SidecarServer {
+			return nil, xerrors.Errorf("service %s is not mongo", name)
+		}
+		u := &url.URL{
+			Scheme: "mongodb",
+			Host:   s.Addr,
+			Path:   "/",
+		}
+		if s.Type != RoutingServer {
+			u.RawQuery = "directConnection=true"
+		}
+		return u, nil
+	}
+
+	return nil, xerrors.Errorf("no service %s", name)
+}
+
+// Shell runs interactive mongosh connected to routing server, inheriting
+// terminal of current process, and blocks until shell exits.
+func (c *Cluster) Shell(ctx context.Context) error {
+	return c.shell(ctx, c.routerURI())
+}
+
+// ShellTo runs interactive mongosh directly connected to named service.
+func (c *Cluster) ShellTo(ctx context.Context, name string) error {
+	uri, err := c.serviceURI(name)
+	if err != nil {
+		return err
+	}
+
+	return c.shell(ctx, uri)
+}
+
+func (c *Cluster) shell(ctx context.Context, uri string) error {
+	cmd := exec.CommandContext(ctx, c.shellBinary(), uri)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	c.log.Info("Starting shell", zap.String("uri", uri))
+	if err := cmd.Run(); err != nil {
+		return xerrors.Errorf("mongosh: %w", err)
+	}
+
+	return nil
+}
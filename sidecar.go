@@ -0,0 +1,243 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+)
+
+// SidecarFunc returns command of auxiliary service. It is called on every
+// start of service, so command can be configured with cluster URI or
+// addresses. Command output is proxied to logs unless set.
+type SidecarFunc func(ctx context.Context) (*exec.Cmd, error)
+
+// Sidecar is auxiliary process run alongside cluster, e.g. application
+// under test, mock KMS or proxy. Sidecar is started when its dependencies
+// are up and stops with cluster before them. Like mongo services, it is
+// listed by Services and can be killed and restarted by name.
+type Sidecar struct {
+	Name    string
+	Command SidecarFunc
+	// DependsOn are names of sidecars, replica sets or routing server
+	// that must be up before sidecar starts, routing server by default,
+	// so sidecar starts when cluster is ready. Replica set is up when it
+	// is initialized, sidecar when it is ready.
+	DependsOn []string
+	// Addr is host:port that sidecar listens on, optional. If set,
+	// sidecar becomes ready when Addr accepts connections.
+	Addr string
+	// Ready reports whether sidecar is ready, overriding Addr check.
+	// Sidecar without Addr and Ready is ready once started.
+	Ready func(ctx context.Context) error
+}
+
+// sidecarPollInterval is interval between readiness checks of sidecar.
+const sidecarPollInterval = time.Millisecond * 100
+
+func (s Sidecar) validate(i int) []string {
+	var problems []string
+	if s.Name == "" {
+		problems = append(problems, fmt.Sprintf("Sidecars[%d].Name is empty: set unique service name", i))
+	}
+	if s.Command == nil {
+		problems = append(problems, fmt.Sprintf("Sidecars[%d].Command is nil: set function that returns command", i))
+	}
+	return problems
+}
+
+// ready returns readiness check of sidecar, nil if sidecar is ready once
+// started.
+func (s Sidecar) ready() func(ctx context.Context) error {
+	if s.Ready != nil || s.Addr == "" {
+		return s.Ready
+	}
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", s.Addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// RegisterService starts auxiliary service of running cluster that is run
+// by fn when dependsOn are up, see Sidecar.
+func (c *Cluster) RegisterService(name string, fn SidecarFunc, dependsOn ...string) error {
+	if name == "" || fn == nil {
+		return xerrors.New("name and function are required")
+	}
+	if c.serve == nil {
+		return xerrors.New("cluster is not running")
+	}
+	if c.hasService(name) {
+		return xerrors.Errorf("service %s is already registered", name)
+	}
+
+	if err := c.addSidecar(Sidecar{Name: name, Command: fn, DependsOn: dependsOn}); err != nil {
+		return err
+	}
+	if _, err := c.graph.levels(); err != nil {
+		c.graph.remove(name)
+		return xerrors.Errorf("dependencies: %w", err)
+	}
+	return c.graph.start(c.serveCtx, c.serve, name)
+}
+
+// addSidecar adds sidecar to dependency graph.
+func (c *Cluster) addSidecar(s Sidecar) error {
+	deps := s.DependsOn
+	if len(deps) == 0 {
+		deps = []string{c.routingName()}
+	}
+	return c.graph.add(s.Name, deps, func(ctx context.Context) error {
+		if err := c.services.add(&service{
+			info: ServiceInfo{
+				Name:      s.Name,
+				Type:      SidecarServer,
+				ShardID:   -1,
+				ReplicaID: -1,
+				Addr:      s.Addr,
+				State:     ServiceStarting,
+			},
+		}); err != nil {
+			return err
+		}
+		return c.runSidecar(ctx, s)
+	})
+}
+
+// runSidecar runs registered sidecar until error or context cancellation.
+func (c *Cluster) runSidecar(ctx context.Context, s Sidecar) error {
+	log := c.serviceLogger(serverOptions{Name: s.Name, Type: SidecarServer})
+	defer func() {
+		retired := false
+		c.services.with(s.Name, func(svc *service) { retired = svc.retired })
+		if retired {
+			_ = c.services.remove(s.Name)
+		}
+	}()
+
+	log.Info("Starting")
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		logReader, logFlush := logProxy(withLevel(log, c.logLevel), g, c.logRedaction, func(e Entry) {
+			c.logs.publish(s.Name, e)
+			c.queueLog(s.Name, e)
+		})
+		defer logFlush()
+
+		return c.runRegistered(gCtx, s.Name, func(ctx context.Context) error {
+			cmd, err := s.Command(ctx)
+			if err == nil && cmd == nil {
+				err = xerrors.New("no command")
+			}
+			if err != nil {
+				c.setExited(s.Name, -1, err)
+				return xerrors.Errorf("command: %w", err)
+			}
+			if cmd.Stdout == nil {
+				cmd.Stdout = logReader
+			}
+			if cmd.Stderr == nil {
+				cmd.Stderr = logReader
+			}
+			setProcessGroup(cmd)
+			if c.parentDeathSignal && !c.detached {
+				setParentDeathSignal(cmd)
+			}
+
+			if err := cmd.Start(); err != nil {
+				c.setExited(s.Name, -1, err)
+				return xerrors.Errorf("start: %w", err)
+			}
+			c.setProcess(s.Name, cmd.Process)
+
+			exited := make(chan struct{})
+			notReady := make(chan error, 1)
+			go func() {
+				if err := c.awaitSidecar(ctx, s, exited); err != nil && ctx.Err() == nil {
+					notReady <- err
+					_ = signalGroup(cmd.Process, syscall.SIGKILL)
+				}
+			}()
+			go func() {
+				select {
+				case <-ctx.Done():
+					_ = signalGroup(cmd.Process, syscall.SIGKILL)
+				case <-exited:
+				}
+			}()
+
+			err = cmd.Wait()
+			close(exited)
+			select {
+			case readyErr := <-notReady:
+				err = readyErr
+			default:
+			}
+			c.services.with(s.Name, func(svc *service) {
+				if svc.terminated {
+					// Sidecar can exit by signal on graceful stop.
+					err = nil
+				}
+			})
+			code := -1
+			if cmd.ProcessState != nil {
+				code = cmd.ProcessState.ExitCode()
+			}
+			c.setExited(s.Name, code, err)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+
+			return err
+		})
+	})
+
+	err := g.Wait()
+	// Sidecar could be never started, so service is marked as stopped
+	// explicitly.
+	c.services.with(s.Name, func(svc *service) {
+		c.services.transition(svc, ServiceStopped)
+	})
+
+	return err
+}
+
+// awaitSidecar marks started sidecar as ready when its readiness check
+// passes. Returns error if sidecar is not ready within setup timeout.
+func (c *Cluster) awaitSidecar(ctx context.Context, s Sidecar, exited <-chan struct{}) error {
+	ready := s.ready()
+	if ready == nil {
+		c.setReady(s.Name)
+		c.graph.markUp(s.Name)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.setupTimeout)
+	defer cancel()
+	for {
+		err := ready(ctx)
+		if err == nil {
+			c.setReady(s.Name)
+			c.graph.markUp(s.Name)
+			return nil
+		}
+
+		select {
+		case <-exited:
+			// Exit is reported by process wait.
+			return nil
+		case <-ctx.Done():
+			return xerrors.Errorf("not ready: %w", err)
+		case <-time.After(sidecarPollInterval):
+		}
+	}
+}
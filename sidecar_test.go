@@ -0,0 +1,145 @@
+//go:build !windows
+// +build !windows
+
+package booga
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/xerrors"
+)
+
+func TestSidecar(t *testing.T) {
+	c := New(Config{Log: zap.NewNop(), SetupTimeout: time.Second * 10})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	g, gCtx := errgroup.WithContext(ctx)
+	c.serve, c.serveCtx = g, gCtx
+	// Sidecars depend on routing server by default.
+	if err := c.graph.add(c.routingName(), nil, func(ctx context.Context) error {
+		c.graph.markUp(c.routingName())
+		<-ctx.Done()
+		return ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.graph.startAll(gCtx, g); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(ctx context.Context) (*exec.Cmd, error) {
+		return exec.Command("sh", "-c", "echo started; exec sleep 60"), nil
+	}
+	if err := c.RegisterService("app", run, "kms"); err == nil {
+		t.Fatal("unknown dependency")
+	}
+	if err := c.RegisterService("kms", run); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RegisterService("app", run, "kms"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RegisterService("app", run); err == nil {
+		t.Fatal("running sidecar replaced")
+	}
+	if err := c.services.waitFor(ctx, "app", func(state ServiceState, registered bool) (bool, error) {
+		return registered && state == ServiceReady, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if stages := c.shutdownStages(); len(stages) != 4 || len(stages[1]) != 1 || stages[1][0] != "app" {
+		t.Errorf("unexpected shutdown stages %v", stages)
+	}
+	if _, err := c.serviceURI("app"); err == nil {
+		t.Error("sidecar should have no mongo URI")
+	}
+
+	if _, err := c.KillWait(ctx, "app"); err != nil {
+		t.Fatal(err)
+	}
+	entries, unsubscribe := c.logs.subscribe("app")
+	defer unsubscribe()
+	if err := c.Restart("app"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-entries:
+		if e.Message != "started" {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	case <-ctx.Done():
+		t.Fatal("no output of restarted sidecar")
+	}
+	if err := c.waitServiceState(ctx, "app", ServiceReady); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.terminate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app", "kms"} {
+		c.services.with(name, func(s *service) {
+			if s.info.State != ServiceStopped || s.exitErr != nil {
+				t.Errorf("%s is %s after stop: %v", name, s.info.State, s.exitErr)
+			}
+		})
+	}
+
+	cancel()
+	if err := g.Wait(); !xerrors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestSidecarNoCommand(t *testing.T) {
+	c := New(Config{Log: zap.NewNop(), SetupTimeout: time.Second * 10})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	g, gCtx := errgroup.WithContext(ctx)
+	c.serve, c.serveCtx = g, gCtx
+	if err := c.graph.add(c.routingName(), nil, func(ctx context.Context) error {
+		c.graph.markUp(c.routingName())
+		<-ctx.Done()
+		return ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.graph.startAll(gCtx, g); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.RegisterService("app", func(ctx context.Context) (*exec.Cmd, error) {
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Wait(); err == nil || !strings.Contains(err.Error(), "no command") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestSidecarValidate(t *testing.T) {
+	err := Config{Sidecars: []Sidecar{
+		{Name: "kms", Command: func(ctx context.Context) (*exec.Cmd, error) { return nil, nil }},
+		{Name: "kms"},
+	}}.Validate()
+	var cfgErr *ConfigError
+	if !xerrors.As(err, &cfgErr) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var sidecarProblems int
+	for _, p := range cfgErr.Problems {
+		if strings.HasPrefix(p, "Sidecars") {
+			sidecarProblems++
+		}
+	}
+	if sidecarProblems != 2 {
+		t.Errorf("unexpected problems %v", cfgErr.Problems)
+	}
+}
@@ -0,0 +1,189 @@
+package booga
+
+import (
+	"context"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// ShardStats is data distribution of namespace on single shard.
+type ShardStats struct {
+	Shard     string
+	Documents int64
+	Size      int64 // uncompressed data size in bytes
+	Chunks    int
+}
+
+// NamespaceStats is data distribution of sharded namespace.
+type NamespaceStats struct {
+	Namespace string
+	Shards    []ShardStats // sorted by shard name
+}
+
+// Shard returns stats of namespace on shard.
+func (s NamespaceStats) Shard(name string) (ShardStats, bool) {
+	for _, shard := range s.Shards {
+		if shard.Shard == name {
+			return shard, true
+		}
+	}
+	return ShardStats{}, false
+}
+
+// Chunks returns total count of chunks.
+func (s NamespaceStats) Chunks() int {
+	var n int
+	for _, shard := range s.Shards {
+		n += shard.Chunks
+	}
+	return n
+}
+
+// ClusterStats is data distribution of all sharded namespaces.
+type ClusterStats struct {
+	Namespaces []NamespaceStats // sorted by namespace
+}
+
+// Namespace returns stats of namespace.
+func (s *ClusterStats) Namespace(ns string) (NamespaceStats, bool) {
+	for _, n := range s.Namespaces {
+		if n.Namespace == ns {
+			return n, true
+		}
+	}
+	return NamespaceStats{}, false
+}
+
+// shardedCollection is document from config.collections.
+type shardedCollection struct {
+	ID      string      `bson:"_id"`
+	UUID    interface{} `bson:"uuid"`
+	Dropped bool        `bson:"dropped"`
+	Key     bson.D      `bson:"key"`
+	Unique  bool        `bson:"unique"`
+}
+
+// chunkCounts returns count of chunks of collection by shard name.
+func chunkCounts(ctx context.Context, client *mongo.Client, coll shardedCollection) (map[string]int, error) {
+	// Chunks are referenced by namespace before 5.0 and by uuid after.
+	chunksFilter := bson.M{"ns": coll.ID}
+	if coll.UUID != nil {
+		chunksFilter = bson.M{"$or": []bson.M{{"ns": coll.ID}, {"uuid": coll.UUID}}}
+	}
+	cur, err := client.Database("config").Collection("chunks").Aggregate(ctx, []bson.M{
+		{"$match": chunksFilter},
+		{"$group": bson.M{"_id": "$shard", "n": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("chunks: %w", err)
+	}
+	var chunks []struct {
+		Shard string `bson:"_id"`
+		N     int    `bson:"n"`
+	}
+	if err := cur.All(ctx, &chunks); err != nil {
+		return nil, xerrors.Errorf("chunks: %w", err)
+	}
+	counts := map[string]int{}
+	for _, c := range chunks {
+		counts[c.Shard] = c.N
+	}
+	return counts, nil
+}
+
+func namespaceStats(ctx context.Context, client *mongo.Client, coll shardedCollection) (*NamespaceStats, error) {
+	dbName, collName, err := splitNamespace(coll.ID)
+	if err != nil {
+		return nil, err
+	}
+	shards := map[string]*ShardStats{}
+	shard := func(name string) *ShardStats {
+		s, ok := shards[name]
+		if !ok {
+			s = &ShardStats{Shard: name}
+			shards[name] = s
+		}
+		return s
+	}
+
+	chunks, err := chunkCounts(ctx, client, coll)
+	if err != nil {
+		return nil, err
+	}
+	for name, n := range chunks {
+		shard(name).Chunks = n
+	}
+
+	cur, err := client.Database(dbName).Collection(collName).Aggregate(ctx, []bson.M{
+		{"$collStats": bson.M{"storageStats": bson.M{}}},
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("collStats: %w", err)
+	}
+	var storage []struct {
+		Shard        string `bson:"shard"`
+		StorageStats struct {
+			Count int64 `bson:"count"`
+			Size  int64 `bson:"size"`
+		} `bson:"storageStats"`
+	}
+	if err := cur.All(ctx, &storage); err != nil {
+		return nil, xerrors.Errorf("collStats: %w", err)
+	}
+	for _, s := range storage {
+		shard(s.Shard).Documents = s.StorageStats.Count
+		shard(s.Shard).Size = s.StorageStats.Size
+	}
+
+	stats := &NamespaceStats{Namespace: coll.ID}
+	for _, s := range shards {
+		stats.Shards = append(stats.Shards, *s)
+	}
+	sort.Slice(stats.Shards, func(i, j int) bool {
+		return stats.Shards[i].Shard < stats.Shards[j].Shard
+	})
+
+	return stats, nil
+}
+
+// Stats returns per-shard data distribution of every sharded namespace
+// except internal ones from "config" database.
+func (c *Cluster) Stats(ctx context.Context) (*ClusterStats, error) {
+	client, err := c.RouterClient(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("connect: %w", err)
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	cur, err := client.Database("config").Collection("collections").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, xerrors.Errorf("find collections: %w", err)
+	}
+	var collections []shardedCollection
+	if err := cur.All(ctx, &collections); err != nil {
+		return nil, xerrors.Errorf("find collections: %w", err)
+	}
+
+	stats := &ClusterStats{}
+	for _, coll := range collections {
+		if coll.Dropped {
+			continue
+		}
+		if db, _, err := splitNamespace(coll.ID); err != nil || db == "config" {
+			continue
+		}
+		ns, err := namespaceStats(ctx, client, coll)
+		if err != nil {
+			return nil, xerrors.Errorf("%s: %w", coll.ID, err)
+		}
+		stats.Namespaces = append(stats.Namespaces, *ns)
+	}
+	sort.Slice(stats.Namespaces, func(i, j int) bool {
+		return stats.Namespaces[i].Namespace < stats.Namespaces[j].Namespace
+	})
+
+	return stats, nil
+}
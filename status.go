@@ -0,0 +1,146 @@
+package booga
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// statusSections are serverStatus sections captured by SnapshotStatus.
+var statusSections = []string{
+	"opcounters",
+	"opcountersRepl",
+	"metrics",
+	"wiredTiger.cache",
+	"globalLock.currentQueue",
+	"globalLock.activeClients",
+}
+
+// flattenStatus adds numeric fields of document to counters by dotted
+// path.
+func flattenStatus(prefix string, doc bson.Raw, counters map[string]int64) {
+	elems, err := doc.Elements()
+	if err != nil {
+		return
+	}
+	for _, e := range elems {
+		key := prefix + e.Key()
+		v := e.Value()
+		if d, ok := v.DocumentOK(); ok {
+			flattenStatus(key+".", d, counters)
+			continue
+		}
+		if n, ok := v.Int64OK(); ok {
+			counters[key] = n
+		} else if n, ok := v.Int32OK(); ok {
+			counters[key] = int64(n)
+		} else if f, ok := v.DoubleOK(); ok {
+			counters[key] = int64(f)
+		}
+	}
+}
+
+// StatusSnapshot is serverStatus counters of every ready service at some
+// point in time, see SnapshotStatus.
+type StatusSnapshot struct {
+	Time time.Time
+	// Services are counters by service name and dotted path, e.g.
+	// "opcounters.query" or "metrics.queryExecutor.collectionScans.total".
+	Services map[string]map[string]int64
+}
+
+func serverStatusCounters(ctx context.Context, client *mongo.Client) (map[string]int64, error) {
+	status, err := client.Database("admin").
+		RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).
+		DecodeBytes()
+	if err != nil {
+		return nil, xerrors.Errorf("serverStatus: %w", err)
+	}
+	counters := map[string]int64{}
+	for _, section := range statusSections {
+		v, err := status.LookupErr(strings.Split(section, ".")...)
+		if err != nil {
+			continue
+		}
+		if d, ok := v.DocumentOK(); ok {
+			flattenStatus(section+".", d, counters)
+		}
+	}
+	return counters, nil
+}
+
+// SnapshotStatus captures counters of opcounters, metrics, cache and
+// queues sections of serverStatus of every ready service. Compare
+// snapshots with DiffStatus.
+func (c *Cluster) SnapshotStatus(ctx context.Context) (*StatusSnapshot, error) {
+	s := &StatusSnapshot{
+		Time:     time.Now(),
+		Services: map[string]map[string]int64{},
+	}
+	for _, t := range []ServerType{ConfigServer, DataServer, RoutingServer} {
+		if err := c.eachService(ctx, t, func(info ServiceInfo, client *mongo.Client) error {
+			counters, err := serverStatusCounters(ctx, client)
+			if err != nil {
+				return err
+			}
+			s.Services[info.Name] = counters
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// StatusDiff is change of counters between snapshots.
+type StatusDiff struct {
+	Duration time.Duration
+	// Services are non-zero changes by service name and dotted path.
+	Services map[string]map[string]int64
+}
+
+// Get returns change of counter of service.
+func (d *StatusDiff) Get(service, path string) int64 {
+	return d.Services[service][path]
+}
+
+// Total returns change of counter summed over services, e.g.
+// Total("metrics.commands.getMore.total").
+func (d *StatusDiff) Total(path string) int64 {
+	var total int64
+	for _, counters := range d.Services {
+		total += counters[path]
+	}
+	return total
+}
+
+// DiffStatus returns change of counters from snapshot a to b. Services and
+// counters missing from either snapshot are skipped.
+func DiffStatus(a, b *StatusSnapshot) *StatusDiff {
+	d := &StatusDiff{
+		Duration: b.Time.Sub(a.Time),
+		Services: map[string]map[string]int64{},
+	}
+	for name, before := range a.Services {
+		after, ok := b.Services[name]
+		if !ok {
+			continue
+		}
+		changes := map[string]int64{}
+		for path, v := range before {
+			w, ok := after[path]
+			if !ok || w == v {
+				continue
+			}
+			changes[path] = w - v
+		}
+		if len(changes) > 0 {
+			d.Services[name] = changes
+		}
+	}
+	return d
+}
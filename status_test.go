@@ -0,0 +1,59 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFlattenStatus(t *testing.T) {
+	doc, err := bson.Marshal(bson.D{
+		{Key: "query", Value: int32(3)},
+		{Key: "queryExecutor", Value: bson.D{
+			{Key: "scanned", Value: int64(10)},
+			{Key: "collectionScans", Value: bson.D{{Key: "total", Value: 2.0}}},
+		}},
+		{Key: "name", Value: "ignored"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counters := map[string]int64{}
+	flattenStatus("metrics.", doc, counters)
+	expected := map[string]int64{
+		"metrics.query":                               3,
+		"metrics.queryExecutor.scanned":               10,
+		"metrics.queryExecutor.collectionScans.total": 2,
+	}
+	if !reflect.DeepEqual(counters, expected) {
+		t.Errorf("unexpected counters %v", counters)
+	}
+}
+
+func TestDiffStatus(t *testing.T) {
+	now := time.Now()
+	a := &StatusSnapshot{Time: now, Services: map[string]map[string]int64{
+		"data-0-0": {"opcounters.query": 5, "opcounters.insert": 1},
+		"data-1-0": {"opcounters.query": 1},
+		"routing":  {"opcounters.query": 1},
+	}}
+	b := &StatusSnapshot{Time: now.Add(time.Second), Services: map[string]map[string]int64{
+		"data-0-0": {"opcounters.query": 7, "opcounters.insert": 1},
+		"data-1-0": {"opcounters.query": 4},
+	}}
+	d := DiffStatus(a, b)
+	if d.Duration != time.Second {
+		t.Errorf("duration %s", d.Duration)
+	}
+	if got := d.Total("opcounters.query"); got != 5 {
+		t.Errorf("total %d", got)
+	}
+	if _, ok := d.Services["data-0-0"]["opcounters.insert"]; ok {
+		t.Error("unchanged counter in diff")
+	}
+	if _, ok := d.Services["routing"]; ok {
+		t.Error("missing service in diff")
+	}
+}
@@ -0,0 +1,51 @@
+package booga
+
+import (
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+// RamDiskEnv is environment variable with path of ram disk that is used
+// for data directories if Config.UseTmpfs is set.
+const RamDiskEnv = "BOOGA_RAMDISK"
+
+// placeOnTmpfs moves cluster directory to tmpfs and returns function that
+// releases it. In order of preference, directory is placed to RamDiskEnv,
+// kept if it is already on tmpfs, mounted as new tmpfs if permitted or
+// placed to shared memory tmpfs.
+func (c *Cluster) placeOnTmpfs() (func(), error) {
+	noop := func() {}
+	if ramDisk := os.Getenv(RamDiskEnv); ramDisk != "" {
+		c.dir = filepath.Join(ramDisk, "booga", filepath.Base(c.dir))
+		c.log.Info("Using ram disk from environment", zap.String("dir", c.dir))
+		return noop, nil
+	}
+	if err := ensureDir(c.dir); err != nil {
+		return nil, xerrors.Errorf("ensure: %w", err)
+	}
+	if isTmpfs(c.dir) {
+		return noop, nil
+	}
+
+	unmount, err := mountTmpfs(c.dir, 0)
+	if err == nil {
+		c.log.Info("Mounted tmpfs", zap.String("dir", c.dir))
+		return func() {
+			if err := unmount(); err != nil {
+				c.log.Warn("Failed to unmount tmpfs", zap.Error(err))
+			}
+		}, nil
+	}
+	c.log.Debug("Failed to mount tmpfs", zap.Error(err))
+
+	if shm := sharedMemoryDir(); shm != "" && isTmpfs(shm) {
+		c.dir = filepath.Join(shm, "booga", filepath.Base(c.dir))
+		c.log.Info("Using shared memory tmpfs", zap.String("dir", c.dir))
+		return noop, nil
+	}
+
+	return nil, xerrors.Errorf("no tmpfs available, set %s", RamDiskEnv)
+}
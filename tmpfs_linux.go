@@ -0,0 +1,36 @@
+package booga
+
+import (
+	"strconv"
+	"syscall"
+)
+
+// tmpfsMagic is file system type of tmpfs, see statfs(2).
+const tmpfsMagic = 0x01021994
+
+func isTmpfs(dir string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return false
+	}
+	return st.Type == tmpfsMagic
+}
+
+// mountTmpfs mounts new tmpfs of size bytes to dir, requires
+// CAP_SYS_ADMIN. Zero size means default limit.
+func mountTmpfs(dir string, size int64) (func() error, error) {
+	data := "mode=0700"
+	if size > 0 {
+		data += ",size=" + strconv.FormatInt(size, 10)
+	}
+	if err := syscall.Mount("tmpfs", dir, "tmpfs", 0, data); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return syscall.Unmount(dir, syscall.MNT_DETACH)
+	}, nil
+}
+
+func sharedMemoryDir() string {
+	return "/dev/shm"
+}
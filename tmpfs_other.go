@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+package booga
+
+import "golang.org/x/xerrors"
+
+func isTmpfs(dir string) bool {
+	return false
+}
+
+func mountTmpfs(dir string, size int64) (func() error, error) {
+	return nil, xerrors.New("tmpfs is supported only on linux")
+}
+
+func sharedMemoryDir() string {
+	return ""
+}
@@ -0,0 +1,42 @@
+package booga
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is name of OpenTelemetry tracer.
+const instrumentationName = "github.com/ernado/booga"
+
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(instrumentationName)
+}
+
+// startSpan starts new span with given name.
+func (c *Cluster) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, and ends span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// serverAttributes returns span attributes of server.
+func serverAttributes(opt serverOptions) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("booga.type", opt.Type.String()),
+		attribute.Int("booga.port", opt.Port),
+	}
+}
@@ -0,0 +1,100 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// ttlMonitorArgs returns mongod arguments that set interval between
+// passes of TTL monitor, 60s by default.
+func ttlMonitorArgs(interval time.Duration) []string {
+	if interval <= 0 {
+		return nil
+	}
+	return []string{"--setParameter", fmt.Sprintf("ttlMonitorSleepSecs=%d", ttlMonitorSecs(interval))}
+}
+
+// ttlMonitorSecs returns interval in whole seconds, at least one.
+func ttlMonitorSecs(interval time.Duration) int {
+	if secs := int(interval / time.Second); secs > 1 {
+		return secs
+	}
+	return 1
+}
+
+func setTTLMonitorSecs(ctx context.Context, client *mongo.Client, secs int) error {
+	return client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "setParameter", Value: 1},
+		{Key: "ttlMonitorSleepSecs", Value: secs},
+	}).Err()
+}
+
+// SetTTLMonitorInterval sets interval between passes of TTL monitor on
+// every shard member, rounded down to seconds, at least one second.
+func (c *Cluster) SetTTLMonitorInterval(ctx context.Context, interval time.Duration) error {
+	return c.eachService(ctx, DataServer, func(_ ServiceInfo, client *mongo.Client) error {
+		return setTTLMonitorSecs(ctx, client, ttlMonitorSecs(interval))
+	})
+}
+
+// ttlPasses returns count of completed TTL monitor passes.
+func ttlPasses(ctx context.Context, client *mongo.Client) (int64, error) {
+	var reply struct {
+		Metrics struct {
+			TTL struct {
+				Passes int64 `bson:"passes"`
+			} `bson:"ttl"`
+		} `bson:"metrics"`
+	}
+	if err := client.Database("admin").
+		RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).
+		Decode(&reply); err != nil {
+		return 0, xerrors.Errorf("serverStatus: %w", err)
+	}
+	return reply.Metrics.TTL.Passes, nil
+}
+
+// RunTTLPass waits until every shard member completes TTL monitor pass
+// that started after call, so documents expired before call are removed
+// from primaries. Interval of TTL monitor is lowered to one second
+// meanwhile.
+func (c *Cluster) RunTTLPass(ctx context.Context) error {
+	return c.eachService(ctx, DataServer, func(_ ServiceInfo, client *mongo.Client) error {
+		var param struct {
+			Secs int `bson:"ttlMonitorSleepSecs"`
+		}
+		if err := client.Database("admin").RunCommand(ctx, bson.D{
+			{Key: "getParameter", Value: 1},
+			{Key: "ttlMonitorSleepSecs", Value: 1},
+		}).Decode(&param); err != nil {
+			return xerrors.Errorf("getParameter: %w", err)
+		}
+		start, err := ttlPasses(ctx, client)
+		if err != nil {
+			return err
+		}
+		if err := setTTLMonitorSecs(ctx, client, 1); err != nil {
+			return xerrors.Errorf("setParameter: %w", err)
+		}
+		defer func() { _ = setTTLMonitorSecs(context.Background(), client, param.Secs) }()
+
+		// Pass that is in progress could start before call.
+		for {
+			passes, err := ttlPasses(ctx, client)
+			if err != nil {
+				return err
+			}
+			if passes >= start+2 {
+				return nil
+			}
+			if !sleep(ctx, time.Millisecond*100) {
+				return ctx.Err()
+			}
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package booga
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"golang.org/x/xerrors"
+)
+
+// txnLimitsArgs returns mongod arguments that set maximum lifetime of
+// transaction and how long transaction waits for locks, zero values keep
+// defaults of 60s and 5ms.
+func txnLimitsArgs(lifetime, lockTimeout time.Duration) []string {
+	var args []string
+	if lifetime > 0 {
+		secs := int(lifetime / time.Second)
+		if secs < 1 {
+			secs = 1
+		}
+		args = append(args, "--setParameter", fmt.Sprintf("transactionLifetimeLimitSeconds=%d", secs))
+	}
+	if lockTimeout > 0 {
+		args = append(args, "--setParameter", fmt.Sprintf("maxTransactionLockRequestTimeoutMillis=%d", lockTimeout.Milliseconds()))
+	}
+	return args
+}
+
+func checkTransactions(shards []ShardSpec) error {
+	if len(shards) < 1 {
+		return xerrors.New("transactions require at least one shard")
+	}
+	for _, s := range shards {
+		if s.Replicas < 1 {
+			return xerrors.New("transactions require at least one replica per shard")
+		}
+	}
+
+	return nil
+}
+
+// SupportsTransactions returns error if topology described by Config
+// does not support multi-document transactions.
+func (opt Config) SupportsTransactions() error {
+	return checkTransactions(opt.shardSpecs())
+}
+
+// RunTxn runs fn in multi-document transaction through routing server.
+//
+// Transaction is committed if fn returns nil and aborted otherwise.
+// Transient transaction errors and unknown commit results are retried,
+// so fn can be called multiple times and should be idempotent.
+func (c *Cluster) RunTxn(ctx context.Context, fn func(sess mongo.SessionContext) error) error {
+	if err := checkTransactions(c.shardSpecs); err != nil {
+		return xerrors.Errorf("check: %w", err)
+	}
+
+	return withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		info, err := getBuildInfo(ctx, client)
+		if err != nil {
+			return xerrors.Errorf("version: %w", err)
+		}
+		if !info.AtLeast(4, 2) {
+			return xerrors.Errorf("sharded transactions are not supported by %s", info.Version)
+		}
+
+		sess, err := client.StartSession()
+		if err != nil {
+			return xerrors.Errorf("start session: %w", err)
+		}
+		defer sess.EndSession(ctx)
+
+		opts := options.Transaction().
+			SetReadConcern(readconcern.Snapshot()).
+			SetWriteConcern(writeconcern.New(writeconcern.WMajority()))
+		if _, err := sess.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			return nil, fn(sessCtx)
+		}, opts); err != nil {
+			return xerrors.Errorf("transaction: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,20 @@
+package booga
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTxnLimitsArgs(t *testing.T) {
+	if args := txnLimitsArgs(0, 0); len(args) != 0 {
+		t.Errorf("defaults: %v", args)
+	}
+	expected := []string{
+		"--setParameter", "transactionLifetimeLimitSeconds=1",
+		"--setParameter", "maxTransactionLockRequestTimeoutMillis=50",
+	}
+	if got := txnLimitsArgs(time.Millisecond*100, time.Millisecond*50); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected args %v", got)
+	}
+}
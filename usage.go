@@ -0,0 +1,95 @@
+package booga
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ResourceUsage of single service process.
+type ResourceUsage struct {
+	Name    string
+	PID     int
+	RSS     int64         // resident set size in bytes
+	CPUTime time.Duration // user and system CPU time
+	OpenFDs int
+	// DiskUsage is total size of data directory in bytes, zero for
+	// routing servers.
+	DiskUsage int64
+}
+
+// dirSize returns total size of regular files in directory.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Files are removed concurrently by mongod.
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// ResourceUsage samples resource usage of every running service.
+//
+// Process statistics are collected from /proc and are available only on
+// Linux, other fields are zero on other platforms.
+func (c *Cluster) ResourceUsage() []ResourceUsage {
+	var result []ResourceUsage
+	for _, s := range c.Services() {
+		if s.State == ServiceStopped || s.PID == 0 {
+			continue
+		}
+		u := ResourceUsage{
+			Name: s.Name,
+			PID:  s.PID,
+		}
+		if err := procUsage(&u); err != nil {
+			c.log.Debug("Failed to read process usage", zap.String("name", s.Name), zap.Error(err))
+		}
+		if s.Type != RoutingServer {
+			size, err := dirSize(filepath.Join(c.dir, s.Name))
+			if err != nil {
+				c.log.Debug("Failed to read disk usage", zap.String("name", s.Name), zap.Error(err))
+			}
+			u.DiskUsage = size
+		}
+		result = append(result, u)
+	}
+
+	return result
+}
+
+// logUsage periodically logs resource usage until context cancellation.
+func (c *Cluster) logUsage(ctx context.Context) {
+	ticker := time.NewTicker(c.usageInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, u := range c.ResourceUsage() {
+				c.log.Info("Resource usage",
+					zap.String("name", u.Name),
+					zap.Int("pid", u.PID),
+					zap.Int64("rss", u.RSS),
+					zap.Duration("cpu", u.CPUTime),
+					zap.Int("fds", u.OpenFDs),
+					zap.Int64("disk", u.DiskUsage),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package booga
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// clockTicks is USER_HZ, which is 100 on virtually every Linux system.
+const clockTicks = 100
+
+// procUsage fills process statistics of u from /proc.
+func procUsage(u *ResourceUsage) error {
+	base := "/proc/" + strconv.Itoa(u.PID)
+
+	statm, err := ioutil.ReadFile(base + "/statm")
+	if err != nil {
+		return xerrors.Errorf("statm: %w", err)
+	}
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return xerrors.New("statm: unexpected format")
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return xerrors.Errorf("statm: %w", err)
+	}
+	u.RSS = pages * int64(os.Getpagesize())
+
+	stat, err := ioutil.ReadFile(base + "/stat")
+	if err != nil {
+		return xerrors.Errorf("stat: %w", err)
+	}
+	// Process name can contain spaces, so skipping it.
+	s := string(stat)
+	fields = strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+	// Fields 14 and 15 of stat are utime and stime, minus pid and comm.
+	if len(fields) < 13 {
+		return xerrors.New("stat: unexpected format")
+	}
+	var ticks int64
+	for _, f := range fields[11:13] {
+		v, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return xerrors.Errorf("stat: %w", err)
+		}
+		ticks += v
+	}
+	u.CPUTime = time.Duration(ticks) * time.Second / clockTicks
+
+	fds, err := ioutil.ReadDir(base + "/fd")
+	if err != nil {
+		return xerrors.Errorf("fd: %w", err)
+	}
+	u.OpenFDs = len(fds)
+
+	return nil
+}
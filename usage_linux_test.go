@@ -0,0 +1,19 @@
+package booga
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProcUsage(t *testing.T) {
+	u := ResourceUsage{PID: os.Getpid()}
+	if err := procUsage(&u); err != nil {
+		t.Fatal(err)
+	}
+	if u.RSS <= 0 {
+		t.Errorf("unexpected rss %d", u.RSS)
+	}
+	if u.OpenFDs <= 0 {
+		t.Errorf("unexpected fds %d", u.OpenFDs)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package booga
+
+func procUsage(u *ResourceUsage) error {
+	return nil
+}
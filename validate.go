@@ -0,0 +1,135 @@
+package booga
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// ValidationResult is result of validate command on collection of single
+// replica set member.
+type ValidationResult struct {
+	Shard     string
+	Member    string // host:port
+	Namespace string
+	Valid     bool
+	Warnings  []string
+	Errors    []string
+}
+
+// ValidationReport is result of ValidateAll.
+type ValidationReport struct {
+	Results []ValidationResult
+}
+
+// Invalid returns results of invalid collections.
+func (r *ValidationReport) Invalid() []ValidationResult {
+	var invalid []ValidationResult
+	for _, res := range r.Results {
+		if !res.Valid {
+			invalid = append(invalid, res)
+		}
+	}
+	return invalid
+}
+
+// Warnings returns results with warnings.
+func (r *ValidationReport) Warnings() []ValidationResult {
+	var warned []ValidationResult
+	for _, res := range r.Results {
+		if len(res.Warnings) > 0 {
+			warned = append(warned, res)
+		}
+	}
+	return warned
+}
+
+// OK reports whether every collection is valid.
+func (r *ValidationReport) OK() bool {
+	return len(r.Invalid()) == 0
+}
+
+// validateMember runs validate on every collection of replica set member.
+func (c *Cluster) validateMember(ctx context.Context, addr string, full bool) ([]ValidationResult, error) {
+	client, err := c.connectMember(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Disconnect(ctx) }()
+
+	databases, err := client.ListDatabaseNames(ctx, bson.M{})
+	if err != nil {
+		return nil, xerrors.Errorf("list databases: %w", err)
+	}
+
+	var results []ValidationResult
+	for _, db := range databases {
+		if db == "local" {
+			continue
+		}
+		collections, err := client.Database(db).ListCollectionNames(ctx, bson.M{"type": "collection"})
+		if err != nil {
+			return nil, xerrors.Errorf("list collections of %s: %w", db, err)
+		}
+		for _, coll := range collections {
+			var reply struct {
+				Valid    bool     `bson:"valid"`
+				Warnings []string `bson:"warnings"`
+				Errors   []string `bson:"errors"`
+			}
+			if err := client.Database(db).
+				RunCommand(ctx, bson.D{
+					{Key: "validate", Value: coll},
+					{Key: "full", Value: full},
+				}).
+				Decode(&reply); err != nil {
+				return nil, xerrors.Errorf("validate %s.%s: %w", db, coll, err)
+			}
+			results = append(results, ValidationResult{
+				Member:    addr,
+				Namespace: db + "." + coll,
+				Valid:     reply.Valid,
+				Warnings:  reply.Warnings,
+				Errors:    reply.Errors,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// ValidateAll runs validate command on every collection of every member
+// of every shard. Full validation is slower, but checks whole data and
+// index structures.
+func (c *Cluster) ValidateAll(ctx context.Context, full bool) (*ValidationReport, error) {
+	report := &ValidationReport{}
+	for shardID := 0; shardID < c.shards; shardID++ {
+		rsName := c.shardReplicaSet(shardID)
+		if err := withClient(ctx, c.shardURI(shardID), func(client *mongo.Client) error {
+			status, err := replSetGetStatus(ctx, client)
+			if err != nil {
+				return err
+			}
+			for _, m := range status.Members {
+				if m.State != statePrimary && m.State != stateSecondary {
+					continue
+				}
+				results, err := c.validateMember(ctx, m.Name, full)
+				if err != nil {
+					return xerrors.Errorf("member %s: %w", m.Name, err)
+				}
+				for _, res := range results {
+					res.Shard = rsName
+					report.Results = append(report.Results, res)
+				}
+			}
+			return nil
+		}); err != nil {
+			return nil, xerrors.Errorf("validate %s: %w", rsName, err)
+		}
+	}
+
+	return report, nil
+}
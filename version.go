@@ -0,0 +1,129 @@
+package booga
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// buildInfo is reply of buildInfo command.
+type buildInfo struct {
+	Version      string   `bson:"version"`
+	VersionArray []int    `bson:"versionArray"`
+	Modules      []string `bson:"modules"`
+	PSMDBVersion string   `bson:"psmdbVersion"` // only for Percona Server
+}
+
+// Distribution is vendor build of MongoDB server.
+type Distribution byte
+
+const (
+	// Community is MongoDB Community Server.
+	Community Distribution = iota
+	// Enterprise is MongoDB Enterprise Server.
+	Enterprise
+	// Percona is Percona Server for MongoDB.
+	Percona
+)
+
+func (d Distribution) String() string {
+	switch d {
+	case Community:
+		return "community"
+	case Enterprise:
+		return "enterprise"
+	case Percona:
+		return "percona"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Distribution) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Distribution returns distribution of server.
+func (b buildInfo) Distribution() Distribution {
+	if b.PSMDBVersion != "" {
+		return Percona
+	}
+	for _, m := range b.Modules {
+		if m == "enterprise" {
+			return Enterprise
+		}
+	}
+	return Community
+}
+
+// parseDistribution detects distribution from output of --version, that
+// includes build info.
+func parseDistribution(versionOutput string) Distribution {
+	switch {
+	case strings.Contains(versionOutput, "psmdbVersion"),
+		strings.Contains(versionOutput, "Percona"):
+		return Percona
+	case strings.Contains(versionOutput, `"enterprise"`):
+		return Enterprise
+	default:
+		return Community
+	}
+}
+
+// AtLeast reports whether server version is major.minor or newer.
+func (b buildInfo) AtLeast(major, minor int) bool {
+	var v [2]int
+	copy(v[:], b.VersionArray)
+	if v[0] != major {
+		return v[0] > major
+	}
+	return v[1] >= minor
+}
+
+func getBuildInfo(ctx context.Context, client *mongo.Client) (*buildInfo, error) {
+	var info buildInfo
+	if err := client.Database("admin").
+		RunCommand(ctx, bson.M{"buildInfo": 1}).
+		Decode(&info); err != nil {
+		return nil, xerrors.Errorf("buildInfo: %w", err)
+	}
+
+	return &info, nil
+}
+
+// requireDistribution returns error if server is not one of distributions.
+func requireDistribution(ctx context.Context, client *mongo.Client, feature string, distributions ...Distribution) error {
+	info, err := getBuildInfo(ctx, client)
+	if err != nil {
+		return xerrors.Errorf("version: %w", err)
+	}
+	d := info.Distribution()
+	for _, expected := range distributions {
+		if d == expected {
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("%s is not supported by %s %s", feature, d, info.Version)
+}
+
+// Distribution returns distribution of cluster servers.
+func (c *Cluster) Distribution(ctx context.Context) (Distribution, error) {
+	var d Distribution
+	if err := withClient(ctx, c.routerURI(), func(client *mongo.Client) error {
+		info, err := getBuildInfo(ctx, client)
+		if err != nil {
+			return err
+		}
+		d = info.Distribution()
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return d, nil
+}
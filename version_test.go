@@ -0,0 +1,50 @@
+package booga
+
+import "testing"
+
+func TestBuildInfoAtLeast(t *testing.T) {
+	for _, tt := range []struct {
+		Version      []int
+		Major, Minor int
+		Result       bool
+	}{
+		{Version: []int{4, 4, 4, 0}, Major: 4, Minor: 2, Result: true},
+		{Version: []int{4, 2, 0, 0}, Major: 4, Minor: 2, Result: true},
+		{Version: []int{4, 0, 1, 0}, Major: 4, Minor: 2, Result: false},
+		{Version: []int{3, 6, 0, 0}, Major: 4, Minor: 0, Result: false},
+		{Version: []int{5, 0, 0, 0}, Major: 4, Minor: 4, Result: true},
+		{Version: nil, Major: 4, Minor: 0, Result: false},
+	} {
+		if got := (buildInfo{VersionArray: tt.Version}).AtLeast(tt.Major, tt.Minor); got != tt.Result {
+			t.Errorf("%v >= %d.%d: got %v", tt.Version, tt.Major, tt.Minor, got)
+		}
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	for _, tt := range []struct {
+		Info   buildInfo
+		Result Distribution
+	}{
+		{Info: buildInfo{Version: "6.0.5"}, Result: Community},
+		{Info: buildInfo{Version: "6.0.5", Modules: []string{"enterprise"}}, Result: Enterprise},
+		{Info: buildInfo{Version: "6.0.5-4", PSMDBVersion: "6.0.5-4"}, Result: Percona},
+	} {
+		if got := tt.Info.Distribution(); got != tt.Result {
+			t.Errorf("%+v: got %s", tt.Info, got)
+		}
+	}
+
+	for _, tt := range []struct {
+		Output string
+		Result Distribution
+	}{
+		{Output: "db version v6.0.5\nBuild Info: {\"modules\": []}", Result: Community},
+		{Output: "db version v6.0.5\nBuild Info: {\"modules\": [\"enterprise\"]}", Result: Enterprise},
+		{Output: "db version v6.0.5-4\nBuild Info: {\"psmdbVersion\": \"6.0.5-4\"}", Result: Percona},
+	} {
+		if got := parseDistribution(tt.Output); got != tt.Result {
+			t.Errorf("%q: got %s", tt.Output, got)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package booga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/xerrors"
+)
+
+// StartupWarning is startup warning of service, e.g. about low
+// vm.max_map_count or enabled transparent huge pages.
+type StartupWarning struct {
+	Service string
+	Entry
+}
+
+// startupWarnings are startup warnings by service name.
+type startupWarnings struct {
+	mux      sync.Mutex
+	services map[string][]Entry
+}
+
+func (w *startupWarnings) set(name string, entries []Entry) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.services == nil {
+		w.services = map[string][]Entry{}
+	}
+	w.services[name] = entries
+}
+
+// parseLogLines parses lines returned by getLog, that are JSON since 4.4
+// and plaintext before.
+func parseLogLines(lines []string) []Entry {
+	var entries []Entry
+	for _, line := range lines {
+		var e Entry
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &e) != nil {
+			e = parseLegacyEntry(line)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// matchWarning returns first pattern that is substring of message or
+// attributes of warning.
+func matchWarning(e Entry, patterns []string) (string, bool) {
+	text := e.Message + " " + fmt.Sprint(e.Attributes)
+	for _, p := range patterns {
+		if strings.Contains(text, p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// checkStartupWarnings records startup warnings of service and returns
+// error if any warning matches Config.FailOnStartupWarnings.
+func (c *Cluster) checkStartupWarnings(ctx context.Context, name string, client *mongo.Client) error {
+	var reply struct {
+		Log []string `bson:"log"`
+	}
+	getLog := func(client *mongo.Client) error {
+		return client.Database("admin").
+			RunCommand(ctx, bson.M{"getLog": "startupWarnings"}).
+			Decode(&reply)
+	}
+	err := getLog(client)
+	if c.auth != nil && isUnauthorized(err) {
+		// Users of restored node exist, so localhost exception no longer
+		// applies.
+		var uri string
+		if uri, err = c.serviceURI(name); err == nil {
+			err = withClient(ctx, uri, getLog)
+		}
+	}
+	if err != nil {
+		return xerrors.Errorf("getLog: %w", err)
+	}
+	entries := parseLogLines(reply.Log)
+	c.warnings.set(name, entries)
+
+	for _, e := range entries {
+		if p, ok := matchWarning(e, c.failOnWarnings); ok {
+			return xerrors.Errorf("startup warning matches %q: %s", p, e.Message)
+		}
+	}
+	return nil
+}
+
+// StartupWarnings returns startup warnings of services sorted by service
+// name.
+func (c *Cluster) StartupWarnings() []StartupWarning {
+	c.warnings.mux.Lock()
+	defer c.warnings.mux.Unlock()
+
+	var result []StartupWarning
+	for name, entries := range c.warnings.services {
+		for _, e := range entries {
+			result = append(result, StartupWarning{Service: name, Entry: e})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Service < result[j].Service
+	})
+	return result
+}
@@ -0,0 +1,25 @@
+package booga
+
+import "testing"
+
+func TestParseLogLines(t *testing.T) {
+	entries := parseLogLines([]string{
+		`{"t":{"$date":"2021-01-01T00:00:00.000+00:00"},"s":"W","c":"CONTROL","id":5123300,"ctx":"initandlisten","msg":"vm.max_map_count is too low","attr":{"currentValue":65530}}`,
+		`2019-03-04T10:00:00.000+0000 I CONTROL  [initandlisten] ** WARNING: /sys/kernel/mm/transparent_hugepage/enabled is 'always'.`,
+	})
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if entries[0].ID != 5123300 || entries[0].System != "CONTROL" {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+	if p, ok := matchWarning(entries[0], []string{"hugepage", "max_map_count"}); !ok || p != "max_map_count" {
+		t.Errorf("json entry is not matched: %q", p)
+	}
+	if _, ok := matchWarning(entries[1], []string{"transparent_hugepage"}); !ok {
+		t.Errorf("legacy entry is not matched: %+v", entries[1])
+	}
+	if _, ok := matchWarning(entries[1], []string{"max_map_count"}); ok {
+		t.Error("unexpected match")
+	}
+}